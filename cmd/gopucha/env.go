@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sjiamnocna/gopucha/internal/env"
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+// runEnv implements "gopucha env [flags] [mapfile]": a training
+// environment driven by JSON lines on stdin, answered on stdout.
+func runEnv(args []string) error {
+	fs := flag.NewFlagSet("env", flag.ExitOnError)
+	maxSteps := fs.Int("max-steps", env.DefaultMaxSteps, "end episodes after this many steps")
+	noMonsters := fs.Bool("no-monsters", false, "disable monster spawning")
+	mapFlag := fs.String("map", "maps/maps.txt", "path to map file")
+	fs.Parse(args)
+
+	mapFile := *mapFlag
+	if fs.NArg() >= 1 {
+		mapFile = fs.Arg(0)
+	}
+	mapsList, err := maps.LoadMapsFromFile(resolveMapFile(mapFile))
+	if err != nil {
+		return fmt.Errorf("failed to load maps: %v", err)
+	}
+
+	if len(mapsList) == 0 {
+		return fmt.Errorf("map file has no levels")
+	}
+
+	e, err := env.New(mapsList, env.Config{MaxSteps: *maxSteps, DisableMonsters: *noMonsters})
+	if err != nil {
+		return err
+	}
+	return env.Serve(os.Stdin, os.Stdout, e)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sjiamnocna/gopucha/internal/gen"
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+// runGen implements "gopucha gen [flags] [outfile]".
+func runGen(args []string) error {
+	fs := flag.NewFlagSet("gen", flag.ExitOnError)
+	width := fs.Int("width", gen.DefaultWidth, "level width")
+	height := fs.Int("height", gen.DefaultHeight, "level height")
+	like := fs.String("like", "", "take width and height from this map file so the levels can join it")
+	density := fs.Float64("density", gen.DefaultDensity, "share of inner cells that become walls")
+	monsters := fs.Int("monsters", gen.DefaultMonsters, "monsters per level")
+	levels := fs.Int("levels", 1, "levels to generate")
+	seed := fs.Int64("seed", 1, "seed of the first level; level i uses seed+i")
+	mirrorVertical := fs.Bool("mirror-vertical", false, "mirror top to bottom as well as left to right")
+	material := fs.String("material", "", "wall material of the levels")
+	outFlag := fs.String("o", "", "write to this file instead of stdout")
+	fs.Parse(args)
+
+	out := *outFlag
+	if fs.NArg() >= 1 {
+		out = fs.Arg(0)
+	}
+	if *levels < 1 {
+		return fmt.Errorf("invalid levels count: %d", *levels)
+	}
+
+	cfg := gen.Config{
+		Width:          *width,
+		Height:         *height,
+		Density:        *density,
+		Monsters:       *monsters,
+		MirrorVertical: *mirrorVertical,
+		Seed:           *seed,
+		Material:       *material,
+	}
+	if *like != "" {
+		pack, err := maps.LoadMapsFromFile(resolveMapFile(*like))
+		if err != nil {
+			return fmt.Errorf("failed to load maps: %v", err)
+		}
+		if len(pack) == 0 {
+			return fmt.Errorf("map file has no levels")
+		}
+		cfg.Width, cfg.Height = pack[0].Width, pack[0].Height
+	}
+
+	generated, err := gen.GenerateLevels(cfg, *levels)
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		return maps.WriteMaps(os.Stdout, generated)
+	}
+	file, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if err := maps.WriteMaps(file, generated); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
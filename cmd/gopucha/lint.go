@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+// runLint implements "gopucha lint [flags] [mapfile...]": every problem of
+// the files, one per line, or as a JSON array with -format json.
+func runLint(args []string) error {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	format := fs.String("format", "text", "output format: text or json")
+	strict := fs.Bool("strict", false, "fail on warnings as well as errors")
+	mapFlag := fs.String("map", "maps/maps.txt", "path to map file")
+	fs.Parse(args)
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unknown format %q (must be text or json)", *format)
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{*mapFlag}
+	}
+	diags := []maps.Diagnostic{}
+	for _, f := range files {
+		found, err := maps.Lint(resolveMapFile(f))
+		if err != nil {
+			return err
+		}
+		diags = append(diags, found...)
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(diags); err != nil {
+			return err
+		}
+	} else {
+		for _, d := range diags {
+			fmt.Println(d)
+		}
+	}
+
+	errs, warnings := 0, 0
+	for _, d := range diags {
+		if d.Severity == maps.SeverityError {
+			errs++
+		} else {
+			warnings++
+		}
+	}
+	if errs > 0 || (*strict && warnings > 0) {
+		return fmt.Errorf("%s and %s found", countNoun(errs, "error"), countNoun(warnings, "warning"))
+	}
+	return nil
+}
+
+// countNoun writes n with noun, in the plural unless n is 1.
+func countNoun(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -1,39 +1,138 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 
+	"github.com/sjiamnocna/gopucha/internal/replay"
+	"github.com/sjiamnocna/gopucha/internal/tui"
 	"github.com/sjiamnocna/gopucha/internal/ui"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := subcommands[os.Args[1]]; ok {
+			if err := cmd(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	noMonsters := flag.Bool("no-monsters", false, "disable monster spawning (debug)")
 	mapFlag := flag.String("map", "maps/maps.txt", "path to map file")
+	recordFlag := flag.String("record", "", "record the game's inputs to this replay file")
+	replayFlag := flag.String("replay", "", "play back a replay file")
+	tuiFlag := flag.Bool("tui", false, "play in the terminal instead of a window")
+	endlessFlag := flag.Bool("endless", false, "loop through the levels with rising difficulty instead of winning")
+	timeAttackFlag := flag.Bool("time-attack", false, "time each level against its par time and your personal bests")
+	coOpFlag := flag.Bool("coop", false, "two players on one keyboard, arrows and WASD")
+	versusFlag := flag.Bool("versus", false, "player two steers a monster with WASD")
+	freeTurnFlag := flag.Bool("free-turn", false, "let the versus monster turn anywhere, not only at walls")
 	flag.Parse()
 
+	if *endlessFlag && *timeAttackFlag {
+		fmt.Fprintln(os.Stderr, "Error: -endless and -time-attack cannot be combined")
+		os.Exit(1)
+	}
+	if *coOpFlag && *versusFlag {
+		fmt.Fprintln(os.Stderr, "Error: -coop and -versus cannot be combined")
+		os.Exit(1)
+	}
+
 	// Default to maps directory if no argument provided
 	mapFile := *mapFlag
 	if flag.NArg() >= 1 {
 		mapFile = flag.Arg(0)
 	}
 
-	// If the path doesn't exist and doesn't contain a directory separator,
-	// try looking in the maps directory
-	if _, err := os.Stat(mapFile); os.IsNotExist(err) && !filepath.IsAbs(mapFile) && filepath.Dir(mapFile) == "." {
-		mapsPath := filepath.Join("maps", mapFile)
-		if _, err := os.Stat(mapsPath); err == nil {
-			mapFile = mapsPath
+	opts := ui.Options{
+		MapFile:         resolveMapFile(mapFile),
+		DisableMonsters: *noMonsters,
+		Endless:         *endlessFlag,
+		TimeAttack:      *timeAttackFlag,
+		CoOp:            *coOpFlag,
+		Versus:          *versusFlag,
+		FreeTurn:        *freeTurnFlag,
+		RecordFile:      *recordFlag,
+	}
+
+	if *replayFlag != "" {
+		rec, err := replay.Load(*replayFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		opts.Replay = rec
+		// The replay knows its map unless one was given explicitly.
+		if !flagPassed("map") && flag.NArg() == 0 {
+			opts.MapFile = ""
 		}
 	}
 
-	// Run GUI game only
-	err := ui.RunGUIGame(mapFile, *noMonsters)
+	var err error
+	if *tuiFlag {
+		err = runTerminal(opts)
+	} else {
+		err = ui.RunGUIGame(opts)
+		// Builds without the GUI fall back to the terminal front-end.
+		if errors.Is(err, ui.ErrNoGUI) {
+			err = runTerminal(opts)
+		}
+	}
 
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// subcommands are dispatched on the first argument; anything else starts a game.
+var subcommands = map[string]func(args []string) error{
+	"sim":    runSim,
+	"scores": runScores,
+	"gen":    runGen,
+	"serve":  runServe,
+	"join":   runJoin,
+	"env":    runEnv,
+	"lint":   runLint,
+}
+
+func runTerminal(opts ui.Options) error {
+	if opts.CoOp || opts.Versus {
+		return errors.New("two-player games need the GUI")
+	}
+	return tui.Run(tui.Options{
+		MapFile:         opts.MapFile,
+		DisableMonsters: opts.DisableMonsters,
+		Endless:         opts.Endless,
+		RecordFile:      opts.RecordFile,
+		Replay:          opts.Replay,
+	})
+}
+
+// resolveMapFile looks in the maps directory when a bare file name does not
+// exist in the working directory.
+func resolveMapFile(mapFile string) string {
+	if _, err := os.Stat(mapFile); os.IsNotExist(err) && !filepath.IsAbs(mapFile) && filepath.Dir(mapFile) == "." {
+		mapsPath := filepath.Join("maps", mapFile)
+		if _, err := os.Stat(mapsPath); err == nil {
+			return mapsPath
+		}
+	}
+	return mapFile
+}
+
+func flagPassed(name string) bool {
+	passed := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			passed = true
+		}
+	})
+	return passed
+}
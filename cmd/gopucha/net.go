@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sjiamnocna/gopucha/internal/maps"
+	"github.com/sjiamnocna/gopucha/internal/netplay"
+	"github.com/sjiamnocna/gopucha/internal/ui"
+)
+
+// runServe implements "gopucha serve [flags] [mapfile]".
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":"+strconv.Itoa(netplay.DefaultPort), "address to listen on")
+	players := fs.Int("players", 2, "players to wait for before the game starts (1 or 2)")
+	noMonsters := fs.Bool("no-monsters", false, "disable monster spawning")
+	tick := fs.Duration("tick", netplay.DefaultTickInterval, "time per game tick")
+	seed := fs.Int64("seed", 0, "game seed, 0 for a random one")
+	mapFlag := fs.String("map", "maps/maps.txt", "path to map file")
+	fs.Parse(args)
+
+	mapFile := *mapFlag
+	if fs.NArg() >= 1 {
+		mapFile = fs.Arg(0)
+	}
+	mapFile = resolveMapFile(mapFile)
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+
+	mapsList, err := maps.LoadMapsFromFile(mapFile)
+	if err != nil {
+		return fmt.Errorf("failed to load maps: %v", err)
+	}
+	srv, err := netplay.NewServer(netplay.ServerConfig{
+		Maps:            mapsList,
+		Players:         *players,
+		DisableMonsters: *noMonsters,
+		Seed:            *seed,
+		TickInterval:    *tick,
+		Countdown:       netplay.DefaultCountdown,
+	})
+	if err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Serving %s on %s, waiting for %d player(s)\n", mapFile, ln.Addr(), *players)
+	return srv.Serve(ln)
+}
+
+// runJoin implements "gopucha join host:port".
+func runJoin(args []string) error {
+	fs := flag.NewFlagSet("join", flag.ExitOnError)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: gopucha join host:port")
+	}
+
+	err := ui.RunGUIGame(ui.Options{Join: fs.Arg(0)})
+	if errors.Is(err, ui.ErrNoGUI) {
+		return fmt.Errorf("joining a game needs the GUI")
+	}
+	return err
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/sjiamnocna/gopucha/internal/scores"
+)
+
+// runScores implements "gopucha scores [flags] [mapfile]".
+func runScores(args []string) error {
+	fs := flag.NewFlagSet("scores", flag.ExitOnError)
+	mapFlag := fs.String("map", "maps/maps.txt", "path to map file")
+	dirFlag := fs.String("dir", "", "score directory (default: the user config directory)")
+	fs.Parse(args)
+
+	mapFile := *mapFlag
+	if fs.NArg() >= 1 {
+		mapFile = fs.Arg(0)
+	}
+	mapFile = resolveMapFile(mapFile)
+
+	dir := *dirFlag
+	if dir == "" {
+		var err error
+		if dir, err = scores.DefaultDir(); err != nil {
+			return err
+		}
+	}
+
+	table, err := scores.Open(dir, mapFile)
+	if err != nil {
+		return err
+	}
+	table.WriteReport(os.Stdout)
+	return nil
+}
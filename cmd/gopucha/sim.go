@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sjiamnocna/gopucha/internal/bot"
+	"github.com/sjiamnocna/gopucha/internal/maps"
+	"github.com/sjiamnocna/gopucha/internal/sim"
+)
+
+// runSim implements "gopucha sim [flags] [mapfile]".
+func runSim(args []string) error {
+	fs := flag.NewFlagSet("sim", flag.ExitOnError)
+	runs := fs.Int("runs", sim.DefaultRuns, "games to simulate per level")
+	maxTicks := fs.Int("ticks", sim.DefaultMaxTicks, "give up on a run after this many ticks")
+	seed := fs.Int64("seed", 1, "seed of the first run; run i uses seed+i")
+	level := fs.Int("level", 0, "only simulate this level (1-based, 0 for all)")
+	player := fs.String("player", "random", "controller for the player: random or autopilot")
+	noMonsters := fs.Bool("no-monsters", false, "disable monster spawning")
+	mapFlag := fs.String("map", "maps/maps.txt", "path to map file")
+	check := fs.Bool("check", false, "fail if any level is never cleared")
+	fs.Parse(args)
+
+	mapFile := *mapFlag
+	if fs.NArg() >= 1 {
+		mapFile = fs.Arg(0)
+	}
+	mapFile = resolveMapFile(mapFile)
+
+	mapsList, err := maps.LoadMapsFromFile(mapFile)
+	if err != nil {
+		return fmt.Errorf("failed to load maps: %v", err)
+	}
+	if *level < 0 || *level > len(mapsList) {
+		return fmt.Errorf("level %d out of range (file has %d levels)", *level, len(mapsList))
+	}
+
+	cfg := sim.Config{
+		Runs:            *runs,
+		MaxTicks:        *maxTicks,
+		Seed:            *seed,
+		DisableMonsters: *noMonsters,
+	}
+	switch *player {
+	case "random":
+		cfg.NewController = sim.NewRandomWalker
+	case "autopilot":
+		cfg.NewController = func(int64) sim.Controller { return bot.New() }
+	default:
+		return fmt.Errorf("unknown player %q", *player)
+	}
+
+	fmt.Printf("%s: %d runs per level, %s player\n\n", mapFile, *runs, *player)
+	var unbeaten []int
+	for i := range mapsList {
+		if *level != 0 && i != *level-1 {
+			continue
+		}
+		stats := sim.RunLevel(i, mapsList[i], cfg)
+		stats.WriteReport(os.Stdout, &mapsList[i])
+		if stats.Cleared == 0 {
+			unbeaten = append(unbeaten, i+1)
+		}
+	}
+	if *check && len(unbeaten) > 0 {
+		return fmt.Errorf("levels never cleared: %v", unbeaten)
+	}
+	return nil
+}
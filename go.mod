@@ -2,7 +2,10 @@ module github.com/sjiamnocna/gopucha
 
 go 1.26.0
 
-require fyne.io/fyne/v2 v2.7.2
+require (
+	fyne.io/fyne/v2 v2.7.2
+	golang.org/x/term v0.29.0
+)
 
 require (
 	fyne.io/systray v1.12.0 // indirect
@@ -0,0 +1,182 @@
+package actors
+
+import (
+	"fmt"
+
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+// NewBrain builds the monster personality described by a map's brain spec.
+func NewBrain(spec maps.BrainSpec) (MonsterBrain, error) {
+	switch spec.Name {
+	case maps.BrainClassic, "":
+		return ClassicBrain{}, nil
+	case maps.BrainChaser:
+		return ChaserBrain{}, nil
+	case maps.BrainAmbusher:
+		return AmbusherBrain{Lead: defaultAmbushLead}, nil
+	case maps.BrainWanderer:
+		return WandererBrain{}, nil
+	case maps.BrainPatrol:
+		if len(spec.Route) == 0 {
+			return nil, fmt.Errorf("patrol brain needs a route")
+		}
+		return &PatrolBrain{Route: append([]maps.StartPos(nil), spec.Route...)}, nil
+	}
+	return nil, fmt.Errorf("unknown monster brain %q", spec.Name)
+}
+
+// PlayerDistances returns the BFS distance field from the player, built at
+// most once per World so every monster in a tick shares it.
+func (w *World) PlayerDistances() [][]int {
+	return w.DistancesTo(w.PlayerX, w.PlayerY)
+}
+
+// DistancesTo is PlayerDistances for an arbitrary target cell.
+func (w *World) DistancesTo(x, y int) [][]int {
+	key := maps.StartPos{X: x, Y: y}
+	if dist, ok := w.targetDist[key]; ok {
+		return dist
+	}
+	if w.targetDist == nil {
+		w.targetDist = make(map[maps.StartPos][][]int)
+	}
+	dist := w.Map.DistancesFrom(x, y)
+	w.targetDist[key] = dist
+	return dist
+}
+
+// huntNearest points the player fields at the player with the shortest
+// path to mo. Ties and unreachable players go to the first player.
+func (w *World) huntNearest(mo *Monster) {
+	if len(w.OtherPlayers) == 0 {
+		return
+	}
+	if w.players == nil {
+		w.players = append([]Target{{X: w.PlayerX, Y: w.PlayerY, Dir: w.PlayerDir}}, w.OtherPlayers...)
+	}
+	best := w.players[0]
+	bestDist := -1
+	for _, p := range w.players {
+		d := w.DistancesTo(p.X, p.Y)[mo.Y][mo.X]
+		if d >= 0 && (bestDist == -1 || d < bestDist) {
+			best = p
+			bestDist = d
+		}
+	}
+	w.PlayerX, w.PlayerY, w.PlayerDir = best.X, best.Y, best.Dir
+}
+
+// Steer keeps the heading and only turns when blocked, preferring the
+// axis with the larger distance to the player.
+func (ClassicBrain) Steer(mo *Monster, w *World) Direction {
+	if !mo.blockedAhead(w) {
+		return mo.Direction
+	}
+	return mo.chooseDirection(w.Map, w.PlayerX, w.PlayerY, w.Monsters)
+}
+
+// Steer only turns when blocked, then takes the neighbour with the shortest
+// path to the player.
+func (ChaserBrain) Steer(mo *Monster, w *World) Direction {
+	if !mo.blockedAhead(w) {
+		return mo.Direction
+	}
+	if d, ok := mo.steerToward(w, w.PlayerDistances()); ok {
+		return d
+	}
+	// Player unreachable from here, fall back to the heuristic.
+	return mo.chooseDirection(w.Map, w.PlayerX, w.PlayerY, w.Monsters)
+}
+
+// Steer only turns when blocked, then heads for the cell Lead steps in
+// front of the player, cutting them off instead of following.
+func (b AmbusherBrain) Steer(mo *Monster, w *World) Direction {
+	if !mo.blockedAhead(w) {
+		return mo.Direction
+	}
+	tx, ty := b.target(w)
+	if d, ok := mo.steerToward(w, w.DistancesTo(tx, ty)); ok {
+		return d
+	}
+	return mo.chooseDirection(w.Map, w.PlayerX, w.PlayerY, w.Monsters)
+}
+
+// target walks up to Lead cells ahead of the player and stops at walls.
+func (b AmbusherBrain) target(w *World) (int, int) {
+	x, y := w.PlayerX, w.PlayerY
+	for i := 0; i < b.Lead; i++ {
+		nx, ny, ok := Step(w.Map, x, y, w.PlayerDir)
+		if !ok {
+			break
+		}
+		x, y = nx, ny
+	}
+	return x, y
+}
+
+// Steer only turns when blocked and then picks any free direction at random.
+func (WandererBrain) Steer(mo *Monster, w *World) Direction {
+	if !mo.blockedAhead(w) {
+		return mo.Direction
+	}
+	free := make([]Direction, 0, 4)
+	for _, d := range []Direction{Up, Down, Left, Right} {
+		if mo.canStep(d, w) {
+			free = append(free, d)
+		}
+	}
+	if len(free) == 0 || w.Rand == nil {
+		return mo.Direction
+	}
+	return free[w.Rand.IntN(len(free))]
+}
+
+// SetDirection records the direction key the monster player pressed last.
+func (b *HumanBrain) SetDirection(d Direction) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.desired = d
+	b.steered = true
+}
+
+// Steer keeps the heading until blocked, then takes the last pressed
+// direction if it is open. With nothing open to turn to, the monster waits
+// at the wall. FreeTurn takes the pressed direction wherever it is open.
+func (b *HumanBrain) Steer(mo *Monster, w *World) Direction {
+	b.mu.Lock()
+	desired, steered := b.desired, b.steered
+	b.mu.Unlock()
+
+	if steered && b.FreeTurn && mo.canStep(desired, w) {
+		return desired
+	}
+	if !mo.blockedAhead(w) {
+		return mo.Direction
+	}
+	if steered && mo.canStep(desired, w) {
+		return desired
+	}
+	return mo.Direction
+}
+
+// Steer walks the shortest path to the next waypoint and moves on to the
+// following one on arrival, looping over the route. Unlike the other
+// personalities a patroller turns wherever its route bends.
+func (b *PatrolBrain) Steer(mo *Monster, w *World) Direction {
+	if len(b.Route) == 0 {
+		return ClassicBrain{}.Steer(mo, w)
+	}
+	for i := 0; i < len(b.Route); i++ {
+		wp := b.Route[b.Next]
+		if wp.X != mo.X || wp.Y != mo.Y {
+			break
+		}
+		b.Next = (b.Next + 1) % len(b.Route)
+	}
+	wp := b.Route[b.Next]
+	if d, ok := mo.steerToward(w, w.DistancesTo(wp.X, wp.Y)); ok {
+		return d
+	}
+	return ClassicBrain{}.Steer(mo, w)
+}
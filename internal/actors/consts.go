@@ -8,3 +8,17 @@ const (
 	Left
 	Right
 )
+
+// Cells an AmbusherBrain aims ahead of the player.
+const defaultAmbushLead = 4
+
+// frightenedWarningTicks is how long before calming down a frightened
+// monster starts to blink.
+const frightenedWarningTicks = 12
+
+var directionNames = map[Direction]string{
+	Up:    "up",
+	Down:  "down",
+	Left:  "left",
+	Right: "right",
+}
@@ -0,0 +1,54 @@
+package actors
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+func (d Direction) String() string {
+	if name, ok := directionNames[d]; ok {
+		return name
+	}
+	return fmt.Sprintf("Direction(%d)", int(d))
+}
+
+// Delta returns the grid step for one move in direction d.
+func (d Direction) Delta() (dx, dy int) {
+	return directionDelta(d)
+}
+
+// Step returns where one move in direction d from (x, y) lands on m,
+// following wrap-around edges and teleporters. ok is false when a wall is
+// in the way.
+func Step(m *maps.Map, x, y int, d Direction) (nx, ny int, ok bool) {
+	dx, dy := directionDelta(d)
+	return m.Neighbor(x, y, dx, dy)
+}
+
+// Opposite returns the reverse heading.
+func (d Direction) Opposite() Direction {
+	switch d {
+	case Up:
+		return Down
+	case Down:
+		return Up
+	case Left:
+		return Right
+	case Right:
+		return Left
+	}
+	return d
+}
+
+// ParseDirection accepts the names produced by Direction.String.
+func ParseDirection(s string) (Direction, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for d, n := range directionNames {
+		if n == name {
+			return d, nil
+		}
+	}
+	return Up, fmt.Errorf("unknown direction %q", s)
+}
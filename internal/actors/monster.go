@@ -7,38 +7,79 @@ func NewMonster(x, y int, dir Direction) *Monster {
 		X:         x,
 		Y:         y,
 		Direction: dir,
+		Home:      maps.StartPos{X: x, Y: y},
 	}
 }
 
-func (mo *Monster) Move(m *maps.Map, playerX, playerY int, monsters []Monster) {
-	newX, newY := mo.X, mo.Y
-	dx, dy := directionDelta(mo.Direction)
-	newX += dx
-	newY += dy
+func (mo *Monster) GetX() int { return mo.X }
 
-	// Check if next cell is blocked by wall or monster
-	wallAhead := m.IsWall(newX, newY)
-	monsterAhead := isOccupiedByMonster(newX, newY, monsters, mo)
+func (mo *Monster) GetY() int { return mo.Y }
 
-	// If blocked, choose a new direction
-	if wallAhead || monsterAhead {
-		mo.Direction = mo.chooseDirection(m, playerX, playerY, monsters)
-		newX, newY = mo.X, mo.Y
-		dx, dy = directionDelta(mo.Direction)
-		newX += dx
-		newY += dy
+// Frighten makes the monster flee for the given number of ticks. A monster
+// that was hunting turns round at once.
+func (mo *Monster) Frighten(ticks int) {
+	if mo.Frightened == 0 {
+		mo.Direction = mo.Direction.Opposite()
+	}
+	mo.Frightened = ticks
+}
+
+func (mo *Monster) IsFrightened() bool { return mo.Frightened > 0 }
+
+// FrightenedEnding reports whether the frightened state is about to run out,
+// so front-ends can warn the player.
+func (mo *Monster) FrightenedEnding() bool {
+	return mo.Frightened > 0 && mo.Frightened <= frightenedWarningTicks
+}
+
+// Respawn puts an eaten monster back on its home cell, hunting again.
+func (mo *Monster) Respawn() {
+	mo.X = mo.Home.X
+	mo.Y = mo.Home.Y
+	mo.Frightened = 0
+}
+
+// Move lets the monster's brain pick a heading and advances one cell if
+// nothing blocks it. Monsters without a brain use ClassicBrain. Frightened
+// monsters ignore their brain, run from the player and only step on even
+// frightened ticks, i.e. at half speed. In co-op the monster hunts, or
+// flees, whichever player is nearest.
+func (mo *Monster) Move(w *World) {
+	w.huntNearest(mo)
+	if mo.Frightened > 0 {
+		if mo.Frightened%2 == 1 {
+			return
+		}
+		mo.Direction = mo.flee(w)
+	} else {
+		brain := mo.Brain
+		if brain == nil {
+			brain = ClassicBrain{}
+		}
+		mo.Direction = brain.Steer(mo, w)
 	}
 
 	// Move if the new cell is walkable
-	if !m.IsWall(newX, newY) && !isOccupiedByMonster(newX, newY, monsters, mo) {
+	newX, newY, ok := Step(w.Map, mo.X, mo.Y, mo.Direction)
+	if ok && !isOccupiedByMonster(newX, newY, w.Monsters, mo) {
 		mo.X = newX
 		mo.Y = newY
 	}
 }
 
+// blockedAhead reports whether a wall or another monster is in front.
+func (mo *Monster) blockedAhead(w *World) bool {
+	return !mo.canStep(mo.Direction, w)
+}
+
+func (mo *Monster) canStep(d Direction, w *World) bool {
+	nx, ny, ok := Step(w.Map, mo.X, mo.Y, d)
+	return ok && !isOccupiedByMonster(nx, ny, w.Monsters, mo)
+}
+
 func (mo *Monster) chooseDirection(m *maps.Map, playerX, playerY int, monsters []Monster) Direction {
-	dx := playerX - mo.X
-	dy := playerY - mo.Y
+	dx := wrapDelta(playerX-mo.X, m.Width, m.WrapsHorizontally())
+	dy := wrapDelta(playerY-mo.Y, m.Height, m.WrapsVertically())
 
 	// Get absolute distances
 	absDx := dx
@@ -81,9 +122,8 @@ func (mo *Monster) chooseDirection(m *maps.Map, playerX, playerY int, monsters [
 
 	// Try each candidate direction in priority order
 	for _, d := range candidates {
-		ndx, ndy := directionDelta(d)
-		nx, ny := mo.X+ndx, mo.Y+ndy
-		if !m.IsWall(nx, ny) && !isOccupiedByMonster(nx, ny, monsters, mo) {
+		nx, ny, ok := Step(m, mo.X, mo.Y, d)
+		if ok && !isOccupiedByMonster(nx, ny, monsters, mo) {
 			return d
 		}
 	}
@@ -91,9 +131,8 @@ func (mo *Monster) chooseDirection(m *maps.Map, playerX, playerY int, monsters [
 	// If preferred directions blocked, try all directions
 	allDirs := []Direction{Up, Down, Left, Right}
 	for _, d := range allDirs {
-		ndx, ndy := directionDelta(d)
-		nx, ny := mo.X+ndx, mo.Y+ndy
-		if !m.IsWall(nx, ny) && !isOccupiedByMonster(nx, ny, monsters, mo) {
+		nx, ny, ok := Step(m, mo.X, mo.Y, d)
+		if ok && !isOccupiedByMonster(nx, ny, monsters, mo) {
 			return d
 		}
 	}
@@ -102,6 +141,69 @@ func (mo *Monster) chooseDirection(m *maps.Map, playerX, playerY int, monsters [
 	return mo.Direction
 }
 
+// flee picks the free neighbour farthest from the player by path length.
+// It only reverses in a dead end and keeps its heading on ties.
+func (mo *Monster) flee(w *World) Direction {
+	dist := w.PlayerDistances()
+	best := mo.Direction
+	bestDist := -2
+	for _, d := range []Direction{mo.Direction, Up, Down, Left, Right} {
+		if d == mo.Direction.Opposite() || !mo.canStep(d, w) {
+			continue
+		}
+		nx, ny, _ := Step(w.Map, mo.X, mo.Y, d)
+		nd := dist[ny][nx]
+		if nd < 0 {
+			nd = w.Map.Width * w.Map.Height // The player cannot get there at all
+		}
+		if nd > bestDist {
+			best = d
+			bestDist = nd
+		}
+	}
+	if bestDist == -2 && mo.canStep(mo.Direction.Opposite(), w) {
+		return mo.Direction.Opposite()
+	}
+	return best
+}
+
+// steerToward picks the free neighbour with the smallest value in dist.
+// ok is false when no free neighbour can reach the target.
+func (mo *Monster) steerToward(w *World, dist [][]int) (Direction, bool) {
+	best := mo.Direction
+	bestDist := -1
+	for _, d := range []Direction{Up, Down, Left, Right} {
+		if !mo.canStep(d, w) {
+			continue
+		}
+		nx, ny, _ := Step(w.Map, mo.X, mo.Y, d)
+		nd := dist[ny][nx]
+		if nd < 0 {
+			continue
+		}
+		if bestDist == -1 || nd < bestDist {
+			best = d
+			bestDist = nd
+		}
+	}
+	return best, bestDist != -1
+}
+
+// wrapDelta shortens an offset along a wrapping axis of the given size so
+// greedy steering heads through the edge when that is the nearer way.
+func wrapDelta(d, size int, wraps bool) int {
+	if !wraps || size == 0 {
+		return d
+	}
+	if d > size/2 {
+		return d - size
+	}
+	if d < -size/2 {
+		return d + size
+	}
+	return d
+}
+
 func isOccupiedByMonster(x, y int, monsters []Monster, self *Monster) bool {
 	for i := range monsters {
 		m := &monsters[i]
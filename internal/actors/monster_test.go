@@ -0,0 +1,258 @@
+package actors
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"testing"
+
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+// gridMap builds an unvalidated map where 'O' is a wall and anything else
+// is empty floor.
+func gridMap(rows ...string) *maps.Map {
+	m := &maps.Map{Width: len(rows[0]), Height: len(rows)}
+	for _, row := range rows {
+		cells := make([]maps.Cell, len(row))
+		for x, ch := range row {
+			if ch == 'O' {
+				cells[x] = maps.Wall
+			}
+		}
+		m.Cells = append(m.Cells, cells)
+	}
+	return m
+}
+
+// The player sits right above the monster behind a wall. The only way round
+// is to the right; the pocket below is a dead end.
+var aroundWallMap = []string{
+	"OOOOOOO",
+	"O.....O",
+	"OOOOO.O",
+	"O.....O",
+	"O.OOOOO",
+	"O.OOOOO",
+	"OOOOOOO",
+}
+
+func TestClassicBrainTurnsByAxis(t *testing.T) {
+	m := gridMap(aroundWallMap...)
+	mo := NewMonster(1, 3, Left)
+	mo.Move(&World{Map: m, PlayerX: 1, PlayerY: 1})
+	if mo.Direction != Down {
+		t.Errorf("classic brain turned %s, want down", mo.Direction)
+	}
+}
+
+func TestChaserBrainFollowsShortestPath(t *testing.T) {
+	m := gridMap(aroundWallMap...)
+	mo := NewMonster(1, 3, Left)
+	mo.Brain = ChaserBrain{}
+	mo.Move(&World{Map: m, PlayerX: 1, PlayerY: 1})
+	if mo.Direction != Right {
+		t.Errorf("chaser turned %s, want right", mo.Direction)
+	}
+	if mo.X != 2 || mo.Y != 3 {
+		t.Errorf("monster at (%d,%d), want (2,3)", mo.X, mo.Y)
+	}
+}
+
+func TestBrainsKeepHeadingUntilBlocked(t *testing.T) {
+	m := gridMap(
+		"OOOOOOO",
+		"O.....O",
+		"O.....O",
+		"OOOOOOO",
+	)
+	brains := []MonsterBrain{ClassicBrain{}, ChaserBrain{}, AmbusherBrain{Lead: 4}, WandererBrain{}}
+	for _, brain := range brains {
+		mo := NewMonster(2, 1, Right)
+		mo.Brain = brain
+		mo.Move(&World{Map: m, PlayerX: 1, PlayerY: 2, Rand: rand.New(rand.NewPCG(1, 2))})
+		if mo.Direction != Right || mo.X != 3 {
+			t.Errorf("%T turned before hitting a wall: %+v", brain, mo)
+		}
+	}
+}
+
+func TestAmbusherAimsAheadOfPlayer(t *testing.T) {
+	m := gridMap(
+		"OOOOOOOOO",
+		"O.......O",
+		"O.OOOOO.O",
+		"O.......O",
+		"OOOOOOOOO",
+	)
+	// Player at the left end of the top corridor running right; the
+	// monster below is blocked heading down and must pick a side. The
+	// chaser goes left toward the player, the ambusher right to cut them off.
+	w := &World{Map: m, PlayerX: 1, PlayerY: 1, PlayerDir: Right}
+
+	chaser := NewMonster(4, 3, Down)
+	chaser.Brain = ChaserBrain{}
+	chaser.Move(w)
+	if chaser.Direction != Left {
+		t.Errorf("chaser turned %s, want left", chaser.Direction)
+	}
+
+	w = &World{Map: m, PlayerX: 1, PlayerY: 1, PlayerDir: Right}
+	ambusher := NewMonster(4, 3, Down)
+	ambusher.Brain = AmbusherBrain{Lead: 6}
+	ambusher.Move(w)
+	if ambusher.Direction != Right {
+		t.Errorf("ambusher turned %s, want right", ambusher.Direction)
+	}
+}
+
+func TestPatrolBrainLoopsRoute(t *testing.T) {
+	m := gridMap(
+		"OOOOOO",
+		"O....O",
+		"O.OO.O",
+		"O....O",
+		"OOOOOO",
+	)
+	route := []maps.StartPos{{X: 4, Y: 1}, {X: 4, Y: 3}, {X: 1, Y: 3}, {X: 1, Y: 1}}
+	mo := NewMonster(1, 1, Up)
+	mo.Brain = &PatrolBrain{Route: route}
+
+	visited := make(map[maps.StartPos]bool)
+	for i := 0; i < 20; i++ {
+		mo.Move(&World{Map: m, PlayerX: 2, PlayerY: 3})
+		visited[maps.StartPos{X: mo.X, Y: mo.Y}] = true
+	}
+	for _, wp := range route {
+		if !visited[wp] {
+			t.Errorf("patrol never reached waypoint %+v", wp)
+		}
+	}
+}
+
+func TestNewBrainFromSpec(t *testing.T) {
+	for _, name := range []string{maps.BrainClassic, maps.BrainChaser, maps.BrainAmbusher, maps.BrainWanderer} {
+		if _, err := NewBrain(maps.BrainSpec{Name: name}); err != nil {
+			t.Errorf("NewBrain(%q) error = %v", name, err)
+		}
+	}
+	if _, err := NewBrain(maps.BrainSpec{Name: maps.BrainPatrol}); err == nil {
+		t.Error("expected an error for a patrol without a route")
+	}
+	if _, err := NewBrain(maps.BrainSpec{Name: "sleepy"}); err == nil {
+		t.Error("expected an error for an unknown brain")
+	}
+}
+
+func TestFrightenedMonsterFleesAtHalfSpeed(t *testing.T) {
+	m := gridMap(
+		"OOOOOOOOO",
+		"O.......O",
+		"OOOOOOOOO",
+	)
+	mo := NewMonster(4, 1, Left)
+	mo.Frighten(4)
+	if mo.Direction != Right {
+		t.Fatalf("frightened monster kept heading %s, want it to turn round", mo.Direction)
+	}
+
+	w := &World{Map: m, PlayerX: 2, PlayerY: 1}
+	var xs []int
+	for mo.Frightened > 0 {
+		mo.Move(w)
+		xs = append(xs, mo.X)
+		mo.Frightened--
+	}
+	if want := []int{5, 5, 6, 6}; !reflect.DeepEqual(xs, want) {
+		t.Errorf("positions %v, want %v", xs, want)
+	}
+
+	mo.Frighten(10)
+	mo.Respawn()
+	if mo.X != 4 || mo.Y != 1 || mo.IsFrightened() {
+		t.Errorf("respawned monster %+v, want home at (4,1) and hunting", mo)
+	}
+}
+
+func TestClassicBrainTakesTunnelWhenShorter(t *testing.T) {
+	m := gridMap(
+		"OOOOOOOOO",
+		".........",
+		"OOOOOOOOO",
+	)
+	m.Wrap = maps.WrapHorizontal
+	mo := NewMonster(1, 1, Up)
+	w := &World{Map: m, PlayerX: 7, PlayerY: 1}
+	if d := (ClassicBrain{}).Steer(mo, w); d != Left {
+		t.Errorf("got %s, want left through the tunnel", d)
+	}
+}
+
+func TestMonsterHuntsNearestPlayer(t *testing.T) {
+	m := gridMap(
+		"OOOOOOOOO",
+		"O.......O",
+		"OOOOOOOOO",
+	)
+	// Player one is farther away on the left, player two close on the right.
+	w := &World{Map: m, PlayerX: 1, PlayerY: 1, OtherPlayers: []Target{{X: 6, Y: 1}}}
+	mo := NewMonster(4, 1, Up)
+	mo.Brain = ChaserBrain{}
+	mo.Move(w)
+	if mo.Direction != Right {
+		t.Errorf("chaser turned %s, want right toward the nearer player", mo.Direction)
+	}
+
+	// The second monster in the same tick picks its own nearest player.
+	other := NewMonster(2, 1, Down)
+	other.Brain = ChaserBrain{}
+	other.Move(w)
+	if other.Direction != Left {
+		t.Errorf("second chaser turned %s, want left", other.Direction)
+	}
+}
+
+func TestHumanBrainTurnsWhenBlocked(t *testing.T) {
+	m := gridMap(
+		"OOOOOO",
+		"O....O",
+		"O.OO.O",
+		"O....O",
+		"OOOOOO",
+	)
+	brain := &HumanBrain{}
+	mo := NewMonster(1, 1, Right)
+	mo.Brain = brain
+	w := &World{Map: m, PlayerX: 1, PlayerY: 3}
+
+	brain.SetDirection(Down)
+	mo.Move(w)
+	if mo.Direction != Right || mo.X != 2 {
+		t.Fatalf("human monster turned before the wall: %+v", mo)
+	}
+	mo.Move(w)
+	mo.Move(w)
+	if mo.X != 4 || mo.Y != 1 {
+		t.Fatalf("monster at (%d,%d), want (4,1)", mo.X, mo.Y)
+	}
+	mo.Move(w)
+	if mo.Direction != Down || mo.Y != 2 {
+		t.Errorf("human monster did not take the pressed turn at the wall: %+v", mo)
+	}
+
+	// Blocked with the pressed direction closed, it waits.
+	brain.SetDirection(Right)
+	mo.Move(w)
+	mo.Move(w)
+	if mo.X != 4 || mo.Y != 3 {
+		t.Errorf("monster at (%d,%d), want to wait at (4,3)", mo.X, mo.Y)
+	}
+
+	free := &HumanBrain{FreeTurn: true}
+	mo = NewMonster(1, 1, Right)
+	mo.Brain = free
+	free.SetDirection(Down)
+	mo.Move(w)
+	if mo.Direction != Down || mo.Y != 2 {
+		t.Errorf("free-turning monster kept going: %+v", mo)
+	}
+}
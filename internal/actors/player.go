@@ -20,8 +20,7 @@ func (p *Player) Move(m *maps.Map) {
 
 	// If desired direction is available, turn immediately.
 	if p.Desired != p.Direction {
-		dx, dy := directionDelta(p.Desired)
-		if !m.IsWall(p.X+dx, p.Y+dy) {
+		if _, _, ok := Step(m, p.X, p.Y, p.Desired); ok {
 			p.Direction = p.Desired
 			p.dropQueued(p.Direction)
 		} else {
@@ -31,16 +30,10 @@ func (p *Player) Move(m *maps.Map) {
 		p.applyQueuedTurn(m)
 	}
 
-	newX, newY := p.X, p.Y
-	dx, dy := directionDelta(p.Direction)
-	newX += dx
-	newY += dy
-
-	if !m.IsWall(newX, newY) {
+	if newX, newY, ok := Step(m, p.X, p.Y, p.Direction); ok {
 		p.X = newX
 		p.Y = newY
 	}
-
 }
 
 func (p *Player) SetDirection(d Direction) {
@@ -67,8 +60,7 @@ func (p *Player) applyQueuedTurn(m *maps.Map) {
 	}
 
 	for i, d := range p.Queue {
-		dx, dy := directionDelta(d)
-		if !m.IsWall(p.X+dx, p.Y+dy) {
+		if _, _, ok := Step(m, p.X, p.Y, d); ok {
 			p.Desired = d
 			p.Direction = d
 			p.Queue = p.Queue[i+1:]
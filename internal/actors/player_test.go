@@ -0,0 +1,60 @@
+package actors
+
+import (
+	"os"
+	"testing"
+
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+func TestPlayerMovement(t *testing.T) {
+	content := `monsters: 0
+OOO
+O-O
+OOO
+`
+	tmpFile, err := os.CreateTemp("", "test_map_*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	tmpFile.WriteString(content)
+	tmpFile.Close()
+
+	mapsList, _ := maps.LoadMapsFromFile(tmpFile.Name())
+	m := &mapsList[0]
+
+	player := NewPlayer(1, 1)
+
+	// Try to move right (should hit wall)
+	player.SetDirection(Right)
+	player.Move(m)
+	if player.X != 1 || player.Y != 1 {
+		t.Errorf("Player should not move through wall, got position (%d, %d)", player.X, player.Y)
+	}
+
+	// Try to move left (should hit wall)
+	player.SetDirection(Left)
+	player.Move(m)
+	if player.X != 1 || player.Y != 1 {
+		t.Errorf("Player should not move through wall, got position (%d, %d)", player.X, player.Y)
+	}
+}
+
+func TestPlayerWalksThroughTunnel(t *testing.T) {
+	m := gridMap(
+		"OOOOO",
+		".....",
+		"OOOOO",
+	)
+	m.Wrap = maps.WrapHorizontal
+
+	player := NewPlayer(1, 1)
+	player.SetDirection(Left)
+	player.Move(m)
+	player.Move(m)
+	if player.X != 4 || player.Y != 1 {
+		t.Errorf("Player should come out the right edge, got position (%d, %d)", player.X, player.Y)
+	}
+}
@@ -1,6 +1,11 @@
 package actors
 
-import "sync"
+import (
+	"math/rand/v2"
+	"sync"
+
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
 
 type Player struct {
 	X         int
@@ -12,7 +17,68 @@ type Player struct {
 }
 
 type Monster struct {
-	X         int
-	Y         int
-	Direction Direction
+	X          int
+	Y          int
+	Direction  Direction
+	Brain      MonsterBrain
+	Home       maps.StartPos // Respawn cell after being eaten
+	Frightened int           // Ticks of fleeing left, 0 while hunting
+}
+
+// MonsterBrain picks a monster's heading for the current tick. Move then
+// advances one cell in that direction if nothing blocks it.
+type MonsterBrain interface {
+	Steer(mo *Monster, w *World) Direction
+}
+
+// World is the per-tick view of the game a brain may inspect. Build a new
+// one every tick; distance fields are cached inside it. With OtherPlayers
+// set, Move points PlayerX, PlayerY and PlayerDir at whichever player is
+// nearest to the moving monster.
+type World struct {
+	Map          *maps.Map
+	PlayerX      int
+	PlayerY      int
+	PlayerDir    Direction
+	OtherPlayers []Target // Further players in co-op
+	Monsters     []Monster
+	Rand         *rand.Rand // Game RNG, keeps random brains reproducible
+	players      []Target   // Every player, filled on the first Move
+	targetDist   map[maps.StartPos][][]int
+}
+
+// Target is a player position monsters can hunt.
+type Target struct {
+	X   int
+	Y   int
+	Dir Direction
+}
+
+// ClassicBrain is the original Pampuch wall-bounce behaviour.
+type ClassicBrain struct{}
+
+// ChaserBrain turns along the shortest path to the player.
+type ChaserBrain struct{}
+
+// AmbusherBrain aims at a cell ahead of the player's heading.
+type AmbusherBrain struct {
+	Lead int
+}
+
+// WandererBrain turns at random.
+type WandererBrain struct{}
+
+// PatrolBrain loops over a fixed route of waypoints.
+type PatrolBrain struct {
+	Route []maps.StartPos
+	Next  int
+}
+
+// HumanBrain hands a monster to a second player. Like the built-in
+// personalities it only turns when blocked, unless FreeTurn is set.
+type HumanBrain struct {
+	FreeTurn bool
+	desired  Direction
+	steered  bool       // A direction has been chosen
+	mu       sync.Mutex // Protects desired and steered
 }
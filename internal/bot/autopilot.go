@@ -0,0 +1,265 @@
+package bot
+
+import (
+	"github.com/sjiamnocna/gopucha/internal/actors"
+	"github.com/sjiamnocna/gopucha/internal/gameplay"
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+func New() *Autopilot {
+	return &Autopilot{Horizon: defaultHorizon}
+}
+
+// Steer applies the suggested direction; it makes Autopilot usable as a
+// simulator controller.
+func (a *Autopilot) Steer(g *gameplay.Game) {
+	if d, ok := a.Suggest(g); ok {
+		g.Player.SetDirection(d)
+	}
+}
+
+// Suggest returns the direction the player should take this tick. ok is
+// false when the player is walled in or there is no game to look at.
+//
+// Monsters keep their heading until something blocks them, so the cells
+// each one may occupy over the next Horizon ticks form a small set. Only
+// first steps from which the player can keep dodging that set until the
+// horizon are considered; among them the one reaching a dot soonest wins,
+// then the one closest to a dot. With no such step the player runs for
+// whatever keeps it alive longest.
+func (a *Autopilot) Suggest(g *gameplay.Game) (actors.Direction, bool) {
+	if g == nil || g.CurrentMap == nil || g.Player == nil {
+		return actors.Up, false
+	}
+	m := g.CurrentMap
+	px, py := g.Player.X, g.Player.Y
+	horizon := a.Horizon
+	if horizon <= 0 {
+		horizon = defaultHorizon
+	}
+
+	threat := predictMonsters(m, g.Monsters, horizon)
+	lasts := survival(m, threat, horizon)
+
+	// Try the current heading first so ties keep the player going straight.
+	dirs := []actors.Direction{g.Player.Direction}
+	for _, d := range allDirections {
+		if d != g.Player.Direction {
+			dirs = append(dirs, d)
+		}
+	}
+
+	var safe []actors.Direction
+	fallback, longest := actors.Up, -1
+	for _, d := range dirs {
+		nx, ny, ok := actors.Step(m, px, py, d)
+		if !ok {
+			continue
+		}
+		l := -1
+		if safeStep(threat, 0, px, py, nx, ny) {
+			l = lasts[1][ny][nx]
+		}
+		if l == horizon {
+			safe = append(safe, d)
+		}
+		if l > longest {
+			fallback, longest = d, l
+		}
+	}
+	if longest == -1 && len(safe) == 0 {
+		// Walled in, or every step walks into a monster.
+		for _, d := range dirs {
+			if _, _, ok := actors.Step(m, px, py, d); ok {
+				return d, true
+			}
+		}
+		return actors.Up, false
+	}
+	if len(safe) == 0 {
+		return fallback, true
+	}
+
+	if d, ok := soonestDot(m, threat, lasts, px, py, safe, horizon); ok {
+		return d, true
+	}
+	dots := dotDistances(m)
+	best, bestDist := safe[0], -1
+	for _, d := range safe {
+		nx, ny, _ := actors.Step(m, px, py, d)
+		dist := dots[ny][nx]
+		if dist != -1 && (bestDist == -1 || dist < bestDist) {
+			best, bestDist = d, dist
+		}
+	}
+	return best, true
+}
+
+// predictMonsters returns, for every tick up to horizon, the cells some
+// monster may occupy. A monster keeps its heading while the cell ahead is
+// open and may turn anywhere once blocked; patrollers and frightened
+// monsters, which turn freely, may step anywhere each tick. Monsters that
+// stay frightened past the horizon are harmless and left out.
+func predictMonsters(m *maps.Map, monsters []actors.Monster, horizon int) []grid {
+	threat := make([]grid, horizon+1)
+	for t := range threat {
+		threat[t] = newGrid(m)
+	}
+
+	for _, mo := range monsters {
+		if mo.Frightened > horizon {
+			continue
+		}
+		_, freeTurns := mo.Brain.(*actors.PatrolBrain)
+		freeTurns = freeTurns || mo.IsFrightened()
+		states := map[monsterState]bool{{x: mo.X, y: mo.Y, dir: mo.Direction}: true}
+		threat[0].set(mo.X, mo.Y)
+		for t := 1; t <= horizon; t++ {
+			next := make(map[monsterState]bool)
+			for s := range states {
+				if nx, ny, ok := actors.Step(m, s.x, s.y, s.dir); ok && !freeTurns {
+					next[monsterState{x: nx, y: ny, dir: s.dir}] = true
+					continue
+				}
+				stuck := true
+				for _, d := range allDirections {
+					if nx, ny, ok := actors.Step(m, s.x, s.y, d); ok {
+						next[monsterState{x: nx, y: ny, dir: d}] = true
+						stuck = false
+					}
+				}
+				if stuck || freeTurns {
+					next[s] = true
+				}
+			}
+			for s := range next {
+				threat[t].set(s.x, s.y)
+			}
+			states = next
+		}
+	}
+	return threat
+}
+
+// survival returns, per tick and cell, the last tick up to horizon the
+// player can stay alive after standing there; horizon means it can dodge
+// every predicted monster all the way.
+func survival(m *maps.Map, threat []grid, horizon int) [][][]int {
+	lasts := make([][][]int, horizon+1)
+	for t := horizon; t >= 0; t-- {
+		lasts[t] = make([][]int, m.Height)
+		for y := range lasts[t] {
+			lasts[t][y] = make([]int, m.Width)
+			for x := range lasts[t][y] {
+				if t == horizon {
+					lasts[t][y][x] = horizon
+					continue
+				}
+				best := t
+				for _, d := range allDirections {
+					nx, ny, ok := actors.Step(m, x, y, d)
+					if ok && safeStep(threat, t, x, y, nx, ny) {
+						best = max(best, lasts[t+1][ny][nx])
+					}
+				}
+				lasts[t][y][x] = best
+			}
+		}
+	}
+	return lasts
+}
+
+// safeStep reports whether moving from (x, y) to (nx, ny) during tick t+1
+// can neither end on a monster nor swap places with one.
+func safeStep(threat []grid, t, x, y, nx, ny int) bool {
+	if threat[t+1][ny][nx] {
+		return false
+	}
+	return !(threat[t][ny][nx] && threat[t+1][y][x])
+}
+
+// soonestDot walks the safe part of the future breadth-first and returns
+// the first step of the earliest path that eats a dot.
+func soonestDot(m *maps.Map, threat []grid, lasts [][][]int, px, py int, safe []actors.Direction, horizon int) (actors.Direction, bool) {
+	type step struct {
+		x, y  int
+		first actors.Direction
+	}
+	var layer []step
+	seen := newGrid(m)
+	for _, d := range safe {
+		nx, ny, _ := actors.Step(m, px, py, d)
+		layer = append(layer, step{x: nx, y: ny, first: d})
+		seen.set(nx, ny)
+	}
+
+	for t := 1; t <= horizon && len(layer) > 0; t++ {
+		for _, s := range layer {
+			if m.HasDot(s.x, s.y) {
+				return s.first, true
+			}
+		}
+		if t == horizon {
+			break
+		}
+		next := layer[:0:0]
+		seen = newGrid(m)
+		for _, s := range layer {
+			for _, d := range allDirections {
+				nx, ny, ok := actors.Step(m, s.x, s.y, d)
+				if !ok || seen[ny][nx] || !safeStep(threat, t, s.x, s.y, nx, ny) || lasts[t+1][ny][nx] < horizon {
+					continue
+				}
+				seen.set(nx, ny)
+				next = append(next, step{x: nx, y: ny, first: s.first})
+			}
+		}
+		layer = next
+	}
+	return actors.Up, false
+}
+
+// dotDistances is a BFS from every remaining dot at once; -1 marks cells
+// no dot can be reached from.
+func dotDistances(m *maps.Map) [][]int {
+	dist := make([][]int, m.Height)
+	var queue []maps.StartPos
+	for y := range dist {
+		dist[y] = make([]int, m.Width)
+		for x := range dist[y] {
+			dist[y][x] = -1
+			if m.HasDot(x, y) {
+				dist[y][x] = 0
+				queue = append(queue, maps.StartPos{X: x, Y: y})
+			}
+		}
+	}
+
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, d := range allDirections {
+			nx, ny, ok := actors.Step(m, p.X, p.Y, d)
+			if !ok || dist[ny][nx] != -1 {
+				continue
+			}
+			dist[ny][nx] = dist[p.Y][p.X] + 1
+			queue = append(queue, maps.StartPos{X: nx, Y: ny})
+		}
+	}
+	return dist
+}
+
+func newGrid(m *maps.Map) grid {
+	g := make(grid, m.Height)
+	for y := range g {
+		g[y] = make([]bool, m.Width)
+	}
+	return g
+}
+
+func (g grid) set(x, y int) {
+	if y >= 0 && y < len(g) && x >= 0 && x < len(g[y]) {
+		g[y][x] = true
+	}
+}
@@ -0,0 +1,109 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/sjiamnocna/gopucha/internal/actors"
+	"github.com/sjiamnocna/gopucha/internal/gameplay"
+	"github.com/sjiamnocna/gopucha/internal/maps"
+	"github.com/sjiamnocna/gopucha/internal/sim"
+)
+
+// gridMap builds an unvalidated map where 'O' is a wall, '-' a dot and
+// anything else empty floor.
+func gridMap(rows ...string) *maps.Map {
+	m := &maps.Map{Width: len(rows[0]), Height: len(rows), MonsterCount: 1, SpeedModifier: 1}
+	for _, row := range rows {
+		cells := make([]maps.Cell, len(row))
+		for x, ch := range row {
+			switch ch {
+			case 'O':
+				cells[x] = maps.Wall
+			case '-':
+				cells[x] = maps.Dot
+			}
+		}
+		m.Cells = append(m.Cells, cells)
+	}
+	return m
+}
+
+func TestSuggestHeadsForNearestDot(t *testing.T) {
+	m := gridMap(
+		"OOOOOOOO",
+		"O -    O",
+		"O O    O",
+		"O     -O",
+		"OOOOOOOO",
+	)
+	g := &gameplay.Game{CurrentMap: m, Player: actors.NewPlayer(1, 1)}
+
+	d, ok := New().Suggest(g)
+	if !ok || d != actors.Right {
+		t.Fatalf("got %v (ok %v), want right toward the adjacent dot", d, ok)
+	}
+}
+
+func TestSuggestAvoidsMonster(t *testing.T) {
+	// The closer dot lies where the monster gets first; the other is safe
+	// and the loop leaves room to dodge afterwards.
+	m := gridMap(
+		"OOOOOOOOOOO",
+		"O-     -  O",
+		"O OOOOOOO O",
+		"O         O",
+		"OOOOOOOOOOO",
+	)
+	g := &gameplay.Game{
+		CurrentMap: m,
+		Player:     actors.NewPlayer(5, 1),
+		Monsters:   []actors.Monster{*actors.NewMonster(9, 1, actors.Left)},
+	}
+
+	d, ok := New().Suggest(g)
+	if !ok || d != actors.Left {
+		t.Fatalf("got %v (ok %v), want left away from the monster", d, ok)
+	}
+}
+
+func TestSuggestFleesWhenNoDotIsSafe(t *testing.T) {
+	m := gridMap(
+		"OOOOOO",
+		"O  - O",
+		"OOOOOO",
+	)
+	g := &gameplay.Game{
+		CurrentMap: m,
+		Player:     actors.NewPlayer(2, 1),
+		Monsters:   []actors.Monster{*actors.NewMonster(4, 1, actors.Left)},
+	}
+
+	d, ok := New().Suggest(g)
+	if !ok || d != actors.Left {
+		t.Fatalf("got %v (ok %v), want left away from the monster", d, ok)
+	}
+}
+
+func TestAutopilotClearsLevelInSimulator(t *testing.T) {
+	m := gridMap(
+		"OOOOOOO",
+		"O-----O",
+		"O-OOO-O",
+		"O-----O",
+		"OOOOOOO",
+	)
+	stats := sim.RunLevel(0, *m, sim.Config{
+		Runs:            5,
+		MaxTicks:        500,
+		Seed:            1,
+		DisableMonsters: true,
+		NewController:   func(int64) sim.Controller { return New() },
+	})
+	if stats.Cleared != 5 {
+		t.Fatalf("cleared %d of 5 runs", stats.Cleared)
+	}
+	// 12 floor cells; a sensible route needs no backtracking.
+	if avg := stats.AvgClearTicks(); avg > 14 {
+		t.Errorf("avg clear ticks %.1f, want a direct route", avg)
+	}
+}
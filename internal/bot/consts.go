@@ -0,0 +1,7 @@
+package bot
+
+import "github.com/sjiamnocna/gopucha/internal/actors"
+
+const defaultHorizon = 16
+
+var allDirections = []actors.Direction{actors.Up, actors.Down, actors.Left, actors.Right}
@@ -0,0 +1,20 @@
+package bot
+
+import "github.com/sjiamnocna/gopucha/internal/actors"
+
+// Autopilot steers the Pampuch figure toward the nearest dot it can reach
+// without being cornered. It is stateless, so one value can serve any
+// number of games.
+type Autopilot struct {
+	// Horizon is how many ticks ahead monster moves are predicted.
+	Horizon int
+}
+
+// monsterState is one position and heading a monster may be in.
+type monsterState struct {
+	x, y int
+	dir  actors.Direction
+}
+
+// grid is a per-cell flag layer of the map.
+type grid [][]bool
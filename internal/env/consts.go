@@ -0,0 +1,27 @@
+package env
+
+const (
+	DefaultMaxSteps = 5000
+
+	// Rewards on top of the game's score delta.
+	levelClearReward = 1000
+	lifeLostPenalty  = -500
+)
+
+const (
+	ActionNone Action = iota // Keep going
+	ActionUp
+	ActionDown
+	ActionLeft
+	ActionRight
+)
+
+// actionNames are the protocol names of the actions, indexed by Action.
+var actionNames = []string{"none", "up", "down", "left", "right"}
+
+// Protocol commands.
+const (
+	cmdReset = "reset"
+	cmdStep  = "step"
+	cmdSpec  = "spec"
+)
@@ -0,0 +1,153 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sjiamnocna/gopucha/internal/actors"
+	"github.com/sjiamnocna/gopucha/internal/gameplay"
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+var (
+	errNoLevels = errors.New("map pack has no levels")
+	errNotReset = errors.New("step before reset")
+)
+
+// New creates an environment over levels. Call Reset before the first Step.
+func New(levels []maps.Map, cfg Config) (*Env, error) {
+	if len(levels) == 0 {
+		return nil, errNoLevels
+	}
+	if cfg.MaxSteps <= 0 {
+		cfg.MaxSteps = DefaultMaxSteps
+	}
+	return &Env{levels: levels, cfg: cfg}, nil
+}
+
+// Reset starts a new episode on the first level. The same seed and actions
+// always give the same episode.
+func (e *Env) Reset(seed int64) (Observation, error) {
+	g := gameplay.NewGameWithSeed(e.levels, e.cfg.DisableMonsters, seed)
+	if g == nil {
+		return Observation{}, errNoLevels
+	}
+	e.game = g
+	e.game.BustPauseTicks = 0
+	e.steps = 0
+	return e.observe(), nil
+}
+
+// Step plays one tick. The reward is the score gained, plus a bonus for
+// clearing a level and a penalty for losing a life. done is set once the
+// game is over, won or out of steps. Stepping before Reset is an error.
+func (e *Env) Step(a Action) (Observation, float64, bool, error) {
+	g := e.game
+	if g == nil {
+		return Observation{}, 0, false, errNotReset
+	}
+	if e.Done() {
+		return e.observe(), 0, true, nil
+	}
+	if d, ok := a.Direction(); ok {
+		g.Player.SetDirection(d)
+	}
+
+	score, lives := g.Score, g.Lives
+	g.Update()
+	e.steps++
+
+	reward := float64(g.Score - score)
+	if g.Lives < lives {
+		reward += lifeLostPenalty
+	}
+	if g.LevelCompleted {
+		reward += levelClearReward
+		g.AdvanceLevel()
+	}
+	return e.observe(), reward, e.Done(), nil
+}
+
+// Done reports whether the episode is over. Before the first Reset there
+// is no episode, which counts as over.
+func (e *Env) Done() bool {
+	if e.game == nil {
+		return true
+	}
+	return e.game.GameOver || e.game.Won || e.steps >= e.cfg.MaxSteps
+}
+
+// Game exposes the running game, e.g. for rendering an episode.
+func (e *Env) Game() *gameplay.Game {
+	return e.game
+}
+
+func (e *Env) observe() Observation {
+	g := e.game
+	m := g.CurrentMap
+	obs := Observation{
+		Tick:     g.Tick,
+		Level:    g.CurrentLevel,
+		Width:    m.Width,
+		Height:   m.Height,
+		Player:   ActorState{X: g.Player.X, Y: g.Player.Y, Dir: g.Player.Direction.String()},
+		DotsLeft: m.CountDots(),
+		Score:    g.Score,
+		Lives:    g.Lives,
+	}
+	for y := 0; y < m.Height; y++ {
+		row := make([]byte, m.Width)
+		for x := range row {
+			switch m.Cells[y][x] {
+			case maps.Wall:
+				row[x] = 'O'
+			case maps.Dot:
+				row[x] = '-'
+			case maps.Energizer:
+				row[x] = '*'
+			default:
+				row[x] = ' '
+			}
+		}
+		obs.Grid = append(obs.Grid, string(row))
+	}
+	obs.Monsters = make([]ActorState, 0, len(g.Monsters))
+	for _, mo := range g.Monsters {
+		obs.Monsters = append(obs.Monsters, ActorState{X: mo.X, Y: mo.Y, Dir: mo.Direction.String(), Frightened: mo.Frightened})
+	}
+	return obs
+}
+
+func (a Action) String() string {
+	if a >= 0 && int(a) < len(actionNames) {
+		return actionNames[a]
+	}
+	return fmt.Sprintf("Action(%d)", int(a))
+}
+
+// Direction is the turn an action asks for; ActionNone asks for none.
+func (a Action) Direction() (actors.Direction, bool) {
+	switch a {
+	case ActionUp:
+		return actors.Up, true
+	case ActionDown:
+		return actors.Down, true
+	case ActionLeft:
+		return actors.Left, true
+	case ActionRight:
+		return actors.Right, true
+	}
+	return actors.Up, false
+}
+
+// ParseAction accepts the names produced by Action.String.
+func ParseAction(s string) (Action, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for a, n := range actionNames {
+		if n == name {
+			return Action(a), nil
+		}
+	}
+	return ActionNone, fmt.Errorf("unknown action %q", s)
+}
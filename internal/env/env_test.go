@@ -0,0 +1,160 @@
+package env
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+const testMap = `playerStart: 1,1
+monsters: 2
+OOOOOOOOOO
+O--------O
+O-OO-OOO-O
+O---*----O
+O-OOO-OO-O
+O--------O
+OOOOOOOOOO
+`
+
+func testLevels(t *testing.T, content string) []maps.Map {
+	t.Helper()
+	levels, err := maps.ReadMaps(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("ReadMaps() error = %v", err)
+	}
+	return levels
+}
+
+func newEnv(t *testing.T, levels []maps.Map, cfg Config) *Env {
+	t.Helper()
+	e, err := New(levels, cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return e
+}
+
+func TestNewRejectsEmptyPack(t *testing.T) {
+	if _, err := New(nil, Config{}); err == nil {
+		t.Error("New() accepted a pack without levels")
+	}
+}
+
+func TestStepBeforeReset(t *testing.T) {
+	e := newEnv(t, testLevels(t, testMap), Config{})
+	if !e.Done() {
+		t.Error("Done() = false before the first Reset")
+	}
+	if _, _, _, err := e.Step(ActionUp); err == nil {
+		t.Error("Step() before Reset returned no error")
+	}
+}
+
+func TestResetIsReproducible(t *testing.T) {
+	e := newEnv(t, testLevels(t, testMap), Config{})
+	actions := []Action{ActionRight, ActionNone, ActionDown, ActionLeft, ActionNone, ActionUp}
+
+	run := func() []Observation {
+		first, err := e.Reset(42)
+		if err != nil {
+			t.Fatalf("Reset() error = %v", err)
+		}
+		obs := []Observation{first}
+		for _, a := range actions {
+			o, _, _, err := e.Step(a)
+			if err != nil {
+				t.Fatalf("Step() error = %v", err)
+			}
+			obs = append(obs, o)
+		}
+		return obs
+	}
+	first, second := run(), run()
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("same seed and actions gave different episodes")
+	}
+
+	obs := first[0]
+	if obs.Width != 10 || obs.Height != 7 || len(obs.Grid) != 7 || obs.Grid[0] != "OOOOOOOOOO" {
+		t.Errorf("grid %dx%d %q", obs.Width, obs.Height, obs.Grid)
+	}
+	if obs.Grid[1][1] != ' ' || obs.Grid[3][4] != '*' || len(obs.Monsters) != 2 {
+		t.Errorf("start cell %q, energizer %q, %d monsters", obs.Grid[1][1], obs.Grid[3][4], len(obs.Monsters))
+	}
+}
+
+func TestStepRewardsAndEnds(t *testing.T) {
+	e := newEnv(t, testLevels(t, `playerStart: 1,1
+OOOOO
+O---O
+OOOOO
+`), Config{DisableMonsters: true, MaxSteps: 10})
+	obs, err := e.Reset(1)
+	if err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if obs.DotsLeft != 2 {
+		t.Fatalf("DotsLeft = %d, want 2", obs.DotsLeft)
+	}
+
+	obs, reward, done, _ := e.Step(ActionRight)
+	if reward != 10 || done || obs.Player.X != 2 {
+		t.Errorf("first step: reward %v, done %v, player x %d", reward, done, obs.Player.X)
+	}
+	obs, reward, done, _ = e.Step(ActionNone)
+	if reward != 10+levelClearReward || !done || obs.DotsLeft != 0 {
+		t.Errorf("clearing step: reward %v, done %v, dots %d", reward, done, obs.DotsLeft)
+	}
+	if _, reward, done, _ := e.Step(ActionLeft); reward != 0 || !done {
+		t.Errorf("step after the end: reward %v, done %v", reward, done)
+	}
+}
+
+func TestServeProtocol(t *testing.T) {
+	e := newEnv(t, testLevels(t, testMap), Config{MaxSteps: 100})
+	in := strings.Join([]string{
+		`{"cmd":"spec"}`,
+		`{"cmd":"step","action":"up"}`,
+		`{"cmd":"reset","seed":3}`,
+		`{"cmd":"step","action":"right"}`,
+		`{"cmd":"step","action":"jump"}`,
+	}, "\n")
+
+	var out bytes.Buffer
+	if err := Serve(strings.NewReader(in), &out, e); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("got %d responses, want 5:\n%s", len(lines), out.String())
+	}
+
+	var resp []response
+	for _, line := range lines {
+		var r response
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("invalid response %q: %v", line, err)
+		}
+		resp = append(resp, r)
+	}
+	if len(resp[0].Actions) != 5 || resp[0].MaxSteps != 100 {
+		t.Errorf("spec = %+v", resp[0])
+	}
+	if resp[1].Error == "" {
+		t.Errorf("step before reset was accepted")
+	}
+	if resp[2].Observation == nil || resp[2].Observation.Tick != 0 {
+		t.Errorf("reset = %+v", resp[2])
+	}
+	if resp[3].Observation == nil || resp[3].Reward == nil || *resp[3].Reward != 10 || resp[3].Done == nil || *resp[3].Done {
+		t.Errorf("step = %s", lines[3])
+	}
+	if !strings.Contains(resp[4].Error, "jump") {
+		t.Errorf("bad action error = %q", resp[4].Error)
+	}
+}
@@ -0,0 +1,60 @@
+package env
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Serve drives e from line-delimited JSON requests on r and answers each
+// with one JSON line on w:
+//
+//	{"cmd":"spec"}                  -> {"actions":[...],"maxSteps":N}
+//	{"cmd":"reset","seed":7}        -> {"observation":{...}}
+//	{"cmd":"step","action":"left"}  -> {"observation":{...},"reward":10,"done":false}
+//
+// A bad request gets {"error":"..."} and the session goes on. Serve returns
+// when r is exhausted.
+func Serve(r io.Reader, w io.Writer, e *Env) error {
+	scanner := bufio.NewScanner(r)
+	enc := json.NewEncoder(w)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if err := enc.Encode(e.handle(line)); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (e *Env) handle(line []byte) response {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return response{Error: fmt.Sprintf("invalid request: %v", err)}
+	}
+	switch req.Cmd {
+	case cmdSpec:
+		return response{Actions: actionNames, MaxSteps: e.cfg.MaxSteps}
+	case cmdReset:
+		obs, err := e.Reset(req.Seed)
+		if err != nil {
+			return response{Error: err.Error()}
+		}
+		return response{Observation: &obs}
+	case cmdStep:
+		a, err := ParseAction(req.Action)
+		if err != nil {
+			return response{Error: err.Error()}
+		}
+		obs, reward, done, err := e.Step(a)
+		if err != nil {
+			return response{Error: err.Error()}
+		}
+		return response{Observation: &obs, Reward: &reward, Done: &done}
+	}
+	return response{Error: fmt.Sprintf("unknown command %q", req.Cmd)}
+}
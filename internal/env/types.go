@@ -0,0 +1,63 @@
+package env
+
+import (
+	"github.com/sjiamnocna/gopucha/internal/gameplay"
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+// Action is what an agent does in one step.
+type Action int
+
+// Config describes the episodes an Env plays.
+type Config struct {
+	MaxSteps        int // Episodes still running after this many steps end as done
+	DisableMonsters bool
+}
+
+// Env runs a level pack as reset/step episodes for learning agents. Each
+// step is one game tick; bust pauses are skipped.
+type Env struct {
+	levels []maps.Map
+	cfg    Config
+	game   *gameplay.Game
+	steps  int
+}
+
+// Observation is the game after a step. Grid uses the map characters: 'O'
+// wall, '-' dot, '*' energizer and ' ' empty; actors are listed apart.
+type Observation struct {
+	Tick     int          `json:"tick"`
+	Level    int          `json:"level"`
+	Width    int          `json:"width"`
+	Height   int          `json:"height"`
+	Grid     []string     `json:"grid"`
+	Player   ActorState   `json:"player"`
+	Monsters []ActorState `json:"monsters"`
+	DotsLeft int          `json:"dotsLeft"`
+	Score    int          `json:"score"`
+	Lives    int          `json:"lives"`
+}
+
+type ActorState struct {
+	X          int    `json:"x"`
+	Y          int    `json:"y"`
+	Dir        string `json:"dir"`
+	Frightened int    `json:"frightened,omitempty"`
+}
+
+// request is one line of the stdin protocol.
+type request struct {
+	Cmd    string `json:"cmd"`
+	Seed   int64  `json:"seed,omitempty"`
+	Action string `json:"action,omitempty"`
+}
+
+// response is one line of the stdout protocol.
+type response struct {
+	Observation *Observation `json:"observation,omitempty"`
+	Reward      *float64     `json:"reward,omitempty"`
+	Done        *bool        `json:"done,omitempty"`
+	Actions     []string     `json:"actions,omitempty"`
+	MaxSteps    int          `json:"maxSteps,omitempty"`
+	Error       string       `json:"error,omitempty"`
+}
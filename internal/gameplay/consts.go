@@ -1,3 +1,33 @@
 package gameplay
 
-const defaultMinMonsterDistance = 5
+const (
+	defaultMinMonsterDistance = 5
+	startLives                = 4
+	// About one second at the default GUI tick interval.
+	defaultBustPauseTicks = 7
+	// About six seconds at the default GUI tick interval.
+	defaultFrightenedTicks = 40
+	// Fixed PCG stream selector; the seed alone picks the sequence.
+	pcgStream = 0x9e3779b97f4a7c15
+
+	snapshotVersion = 1
+	snapshotMagic   = "gopucha-save"
+	snapshotGrid    = "---"
+
+	dotScore       = 10
+	energizerScore = 50
+	catchScore     = 500 // Monster player's reward for a catch in versus
+
+	// Each endless cycle adds a monster to levels that have any, up to
+	// endlessMaxExtraMonsters, speeds up by endlessSpeedStep of the level's
+	// speedModifier up to endlessMaxSpeed, and raises the score multiplier
+	// by endlessScoreStep.
+	endlessMaxExtraMonsters = 4
+	endlessSpeedStep        = 0.1
+	endlessMaxSpeed         = 2.0
+	endlessScoreStep        = 0.5
+)
+
+// monsterScores rewards each further monster eaten on one energizer; the
+// last value repeats.
+var monsterScores = []int{200, 400, 800, 1600}
@@ -2,61 +2,164 @@ package gameplay
 
 import (
 	"fmt"
-	"math/rand"
+	"io"
+	"math/rand/v2"
+	"strings"
 	"time"
 
 	"github.com/sjiamnocna/gopucha/internal/actors"
 	"github.com/sjiamnocna/gopucha/internal/maps"
 )
 
+// NewGame creates a game seeded from the wall clock. Use NewGameWithSeed
+// when the run has to be reproducible.
 func NewGame(mapsList []maps.Map, disableMonsters bool) *Game {
+	return NewGameWithSeed(mapsList, disableMonsters, time.Now().UnixNano())
+}
+
+// NewGameWithSeed creates a game whose random placement is driven only by
+// seed, so the same maps, seed and inputs always produce the same state.
+// The maps are copied, the caller's slice is never modified.
+func NewGameWithSeed(mapsList []maps.Map, disableMonsters bool, seed int64) *Game {
+	return newGame(mapsList, disableMonsters, seed, false)
+}
+
+// NewCoOpGame creates a seeded game for two players sharing the board. Each
+// player has their own score and lives; the game is over once both are out.
+func NewCoOpGame(mapsList []maps.Map, disableMonsters bool, seed int64) *Game {
+	return newGame(mapsList, disableMonsters, seed, true)
+}
+
+// NewVersusGame creates a seeded game where a second player steers the
+// first monster of every level through Hunter; levels without monsters get
+// one. freeTurn lets that monster turn anywhere instead of only at walls.
+func NewVersusGame(mapsList []maps.Map, seed int64, freeTurn bool) *Game {
+	g := newGame(mapsList, false, seed, false)
+	if g == nil {
+		return nil
+	}
+	g.Versus = true
+	g.Hunter = &actors.HumanBrain{FreeTurn: freeTurn}
+	g.placeMonsters()
+	return g
+}
+
+func newGame(mapsList []maps.Map, disableMonsters bool, seed int64, coOp bool) *Game {
 	if len(mapsList) == 0 {
 		return nil
 	}
 
+	src := rand.NewPCG(uint64(seed), uint64(seed)^pcgStream)
 	g := &Game{
-		Maps:            mapsList,
+		Maps:            cloneMaps(mapsList),
+		levels:          cloneMaps(mapsList),
 		CurrentLevel:    0,
 		Score:           0,
-		Lives:           4,
+		Lives:           startLives,
+		CoOp:            coOp,
 		DisableMonsters: disableMonsters,
+		Seed:            seed,
+		BustPauseTicks:  defaultBustPauseTicks,
+		FrightenedTicks: defaultFrightenedTicks,
+		rng:             rand.New(src),
+		src:             src,
+	}
+	if coOp {
+		g.Lives2 = startLives
 	}
 
 	g.LoadLevel(0)
 	return g
 }
 
+func cloneMaps(mapsList []maps.Map) []maps.Map {
+	cloned := make([]maps.Map, len(mapsList))
+	for i := range mapsList {
+		cloned[i] = mapsList[i].Clone()
+	}
+	return cloned
+}
+
+// LoadLevel starts level. Past the last level the game is won, or in
+// endless mode the next cycle starts over with fresh copies of the levels.
 func (g *Game) LoadLevel(level int) {
 	if level >= len(g.Maps) {
-		g.Won = true
-		return
+		if !g.Endless {
+			g.Won = true
+			return
+		}
+		g.Cycle++
+		g.Maps = cloneMaps(g.levels)
+		level = 0
 	}
 
 	g.CurrentLevel = level
 	g.CurrentMap = &g.Maps[level]
 	g.CurrentSpeedModifier = g.CurrentMap.SpeedModifier
+	if g.Cycle > 0 {
+		g.CurrentSpeedModifier = min(g.CurrentSpeedModifier*(1+endlessSpeedStep*float64(g.Cycle)), endlessMaxSpeed)
+	}
+
+	g.levelStartTick = g.Tick
 
 	g.placePlayer()
-	// Remove dot at player's starting position
+	// Remove dots at the players' starting positions
 	g.CurrentMap.EatDot(g.Player.X, g.Player.Y)
+	if g.Player2 != nil {
+		g.CurrentMap.EatDot(g.Player2.X, g.Player2.Y)
+	}
 	g.placeMonsters()
 }
 
+// AdvanceLevel moves on after LevelCompleted; past the last level the game
+// is won.
+func (g *Game) AdvanceLevel() {
+	g.LevelCompleted = false
+	g.LoadLevel(g.CurrentLevel + 1)
+}
+
 func (g *Game) placePlayer() {
 	// Reset player to starting position with cleared input queue
-	if g.CurrentMap.PlayerStart != nil {
-		g.Player = actors.NewPlayer(g.CurrentMap.PlayerStart.X, g.CurrentMap.PlayerStart.Y)
-		return
+	g.Player = g.newPlayerAt(g.CurrentMap.PlayerStart, nil)
+	if g.CoOp {
+		used := map[string]bool{fmt.Sprintf("%d,%d", g.Player.X, g.Player.Y): true}
+		g.Player2 = g.newPlayerAt(g.CurrentMap.Player2Start, used)
+	}
+}
+
+// TwoPlayers reports a co-op or versus game. Replays, saves and score
+// tables hold one player, so front-ends keep these games out of them.
+func (g *Game) TwoPlayers() bool {
+	return g.CoOp || g.Versus
+}
+
+// newPlayerAt puts a player on start, or on a random free cell when the
+// level has no such start.
+func (g *Game) newPlayerAt(start *maps.StartPos, exclude map[string]bool) *actors.Player {
+	if start != nil {
+		return actors.NewPlayer(start.X, start.Y)
 	}
 
-	pos, ok := g.randomWalkable(nil)
+	pos, ok := g.randomWalkable(exclude)
 	if ok {
-		g.Player = actors.NewPlayer(pos.X, pos.Y)
-		return
+		return actors.NewPlayer(pos.X, pos.Y)
 	}
 
 	// Fallback
-	g.Player = actors.NewPlayer(1, 1)
+	return actors.NewPlayer(1, 1)
+}
+
+// Players returns the players still on the board, player one first. A
+// co-op player who lost all lives sits out until the game ends.
+func (g *Game) Players() []*actors.Player {
+	players := make([]*actors.Player, 0, 2)
+	if g.Lives > 0 {
+		players = append(players, g.Player)
+	}
+	if g.Player2 != nil && g.Lives2 > 0 {
+		players = append(players, g.Player2)
+	}
+	return players
 }
 
 func (g *Game) placeMonsters() {
@@ -69,6 +172,12 @@ func (g *Game) placeMonsters() {
 	if numMonsters < 0 {
 		numMonsters = 0
 	}
+	if numMonsters > 0 {
+		numMonsters += min(g.Cycle, endlessMaxExtraMonsters)
+	}
+	if g.Versus {
+		numMonsters = max(numMonsters, 1)
+	}
 	if numMonsters == 0 {
 		g.Monsters = nil
 		return
@@ -78,7 +187,10 @@ func (g *Game) placeMonsters() {
 	g.Monsters = []actors.Monster{}
 	used := make(map[string]bool)
 	used[fmt.Sprintf("%d,%d", g.Player.X, g.Player.Y)] = true
-	distMap := distanceMapFrom(g.CurrentMap, g.Player.X, g.Player.Y)
+	if g.Player2 != nil {
+		used[fmt.Sprintf("%d,%d", g.Player2.X, g.Player2.Y)] = true
+	}
+	distMap := g.CurrentMap.DistancesFrom(g.Player.X, g.Player.Y)
 
 	// Use explicit starts first
 	startIdx := 0
@@ -113,7 +225,16 @@ func (g *Game) placeMonsters() {
 		}
 
 		dir := actors.Direction(i % 4)
-		g.Monsters = append(g.Monsters, *actors.NewMonster(x, y, dir))
+		monster := actors.NewMonster(x, y, dir)
+		brain, err := actors.NewBrain(g.CurrentMap.BrainFor(i))
+		if err != nil {
+			brain = actors.ClassicBrain{}
+		}
+		monster.Brain = brain
+		g.Monsters = append(g.Monsters, *monster)
+	}
+	if g.Hunter != nil && len(g.Monsters) > 0 {
+		g.Monsters[0].Brain = g.Hunter
 	}
 }
 
@@ -139,54 +260,10 @@ func (g *Game) randomWalkableWithMinDistance(exclude map[string]bool, distMap []
 		return maps.StartPos{}, false
 	}
 
-	idx := rand.Intn(len(positions))
+	idx := g.rng.IntN(len(positions))
 	return positions[idx], true
 }
 
-func distanceMapFrom(m *maps.Map, startX, startY int) [][]int {
-	dist := make([][]int, m.Height)
-	for y := 0; y < m.Height; y++ {
-		dist[y] = make([]int, m.Width)
-		for x := 0; x < m.Width; x++ {
-			dist[y][x] = -1
-		}
-	}
-
-	if startX < 0 || startY < 0 || startX >= m.Width || startY >= m.Height {
-		return dist
-	}
-	if m.IsWall(startX, startY) {
-		return dist
-	}
-
-	queueX := []int{startX}
-	queueY := []int{startY}
-	dist[startY][startX] = 0
-
-	for len(queueX) > 0 {
-		x := queueX[0]
-		y := queueY[0]
-		queueX = queueX[1:]
-		queueY = queueY[1:]
-
-		neighbors := [][2]int{{x + 1, y}, {x - 1, y}, {x, y + 1}, {x, y - 1}}
-		for _, n := range neighbors {
-			nx, ny := n[0], n[1]
-			if nx < 0 || ny < 0 || nx >= m.Width || ny >= m.Height {
-				continue
-			}
-			if m.IsWall(nx, ny) || dist[ny][nx] != -1 {
-				continue
-			}
-			dist[ny][nx] = dist[y][x] + 1
-			queueX = append(queueX, nx)
-			queueY = append(queueY, ny)
-		}
-	}
-
-	return dist
-}
-
 func (g *Game) randomWalkable(exclude map[string]bool) (maps.StartPos, bool) {
 	positions := make([]maps.StartPos, 0)
 	for y := 0; y < g.CurrentMap.Height; y++ {
@@ -206,7 +283,7 @@ func (g *Game) randomWalkable(exclude map[string]bool) (maps.StartPos, bool) {
 		return maps.StartPos{}, false
 	}
 
-	idx := rand.Intn(len(positions))
+	idx := g.rng.IntN(len(positions))
 	return positions[idx], true
 }
 
@@ -215,13 +292,16 @@ func (g *Game) Update() {
 		return
 	}
 
+	g.Tick++
+
 	// Clear last-tick flags so UI doesn't stay in death/pause state.
 	g.LifeLost = false
 	g.BustPaused = false
 
 	// Pause briefly after a bust so the collision is visible.
 	if g.pendingRespawn {
-		if time.Now().Before(g.bustPauseUntil) {
+		if g.bustPauseLeft > 0 {
+			g.bustPauseLeft--
 			g.BustPaused = true
 			return
 		}
@@ -236,61 +316,75 @@ func (g *Game) Update() {
 		oldMonsterPos[i] = [2]int{g.Monsters[i].X, g.Monsters[i].Y}
 	}
 
-	// Store player position before moving
-	oldPlayerX, oldPlayerY := g.Player.X, g.Player.Y
-
-	// Move player
-	g.Player.Move(g.CurrentMap)
+	// Move the players, storing where they came from
+	players := g.Players()
+	if len(players) == 0 {
+		g.GameOver = true
+		return
+	}
+	oldPlayerPos := make([][2]int, len(players))
+	for i, p := range players {
+		oldPlayerPos[i] = [2]int{p.X, p.Y}
+		p.Move(g.CurrentMap)
+	}
 
-	// Check if player ate a dot
-	if g.CurrentMap.HasDot(g.Player.X, g.Player.Y) {
-		g.CurrentMap.EatDot(g.Player.X, g.Player.Y)
-		baseScore := 10
-		adjustedScore := int(float64(baseScore) * g.CurrentSpeedModifier)
-		g.Score += adjustedScore
-		g.DotEaten = true
-	} else {
-		g.DotEaten = false
+	// Check if a player ate a dot
+	g.DotEaten, g.EnergizerEaten = g.eatDot(g.Player)
+	g.DotEaten2, g.EnergizerEaten2 = g.eatDot(g.Player2)
+	if g.EnergizerEaten || g.EnergizerEaten2 {
+		g.eatStreak = 0
+		for i := range g.Monsters {
+			g.Monsters[i].Frighten(g.FrightenedTicks)
+		}
 	}
 
-	// Move monsters
+	// Move monsters; distance fields in the world are shared by all of them this tick.
+	world := &actors.World{
+		Map:       g.CurrentMap,
+		PlayerX:   players[0].X,
+		PlayerY:   players[0].Y,
+		PlayerDir: players[0].Direction,
+		Monsters:  g.Monsters,
+		Rand:      g.rng,
+	}
+	for _, p := range players[1:] {
+		world.OtherPlayers = append(world.OtherPlayers, actors.Target{X: p.X, Y: p.Y, Dir: p.Direction})
+	}
 	for i := range g.Monsters {
-		g.Monsters[i].Move(g.CurrentMap, g.Player.X, g.Player.Y, g.Monsters)
+		g.Monsters[i].Move(world)
+		if g.Monsters[i].Frightened > 0 {
+			g.Monsters[i].Frightened--
+		}
 	}
 
 	// Check collision with monsters (including position swaps)
-	for i, monster := range g.Monsters {
-		// Same cell collision
-		if g.Player.X == monster.X && g.Player.Y == monster.Y {
-			g.Lives--
-			if g.Lives <= 0 {
-				g.GameOver = true
-				g.LifeLost = false
-			} else {
-				g.LifeLost = true
-				g.BustPaused = true
-				g.pendingRespawn = true
-				g.bustPauseUntil = time.Now().Add(1 * time.Second)
+	g.MonsterEaten = false
+	for pi, p := range players {
+		for i := range g.Monsters {
+			monster := &g.Monsters[i]
+			sameCell := p.X == monster.X && p.Y == monster.Y
+			// Swap collision (player and monster passed through each other)
+			swapped := p.X == oldMonsterPos[i][0] && p.Y == oldMonsterPos[i][1] &&
+				monster.X == oldPlayerPos[pi][0] && monster.Y == oldPlayerPos[pi][1]
+			if !sameCell && !swapped {
+				continue
 			}
-			return
-		}
 
-		// Swap collision (player and monster passed through each other)
-		if g.Player.X == oldMonsterPos[i][0] && g.Player.Y == oldMonsterPos[i][1] &&
-			monster.X == oldPlayerX && monster.Y == oldPlayerY {
-			// Snap the monster onto the player's cell so the bust is visible.
-			g.Monsters[i].X = g.Player.X
-			g.Monsters[i].Y = g.Player.Y
-			g.Lives--
-			if g.Lives <= 0 {
-				g.GameOver = true
-				g.LifeLost = false
-			} else {
-				g.LifeLost = true
-				g.BustPaused = true
-				g.pendingRespawn = true
-				g.bustPauseUntil = time.Now().Add(1 * time.Second)
+			if monster.IsFrightened() {
+				g.eatMonster(monster, g.scoreOf(p))
+				continue
 			}
+
+			if swapped {
+				// Snap the monster onto the player's cell so the bust is visible.
+				monster.X = p.X
+				monster.Y = p.Y
+			}
+			if g.Hunter != nil && monster.Brain == g.Hunter {
+				g.Catches++
+				g.HunterScore += catchScore
+			}
+			g.bust(p)
 			return
 		}
 	}
@@ -298,20 +392,109 @@ func (g *Game) Update() {
 	// Check if all dots are eaten
 	if g.CurrentMap.CountDots() == 0 {
 		// Mark level as completed, GUI will handle pause and advance
+		if !g.LevelCompleted {
+			g.Splits = append(g.Splits, g.LevelTicks())
+		}
 		g.LevelCompleted = true
 	}
 }
 
-func (g *Game) Render() {
-	g.CurrentMap.Render(g.Player.X, g.Player.Y, g.Monsters)
-	fmt.Printf("\nLevel: %d | Score: %d | Dots: %d\n", g.CurrentLevel+1, g.Score, g.CurrentMap.CountDots())
-	fmt.Println("Controls: W=Up, S=Down, A=Left, D=Right, Q=Quit")
+// LevelTicks is how many ticks the current level has been played, bust
+// pauses included.
+func (g *Game) LevelTicks() int {
+	return g.Tick - g.levelStartTick
+}
+
+// eatDot lets player p eat what lies on their cell and scores it. It
+// reports whether p ate and whether that was an energizer.
+func (g *Game) eatDot(p *actors.Player) (ate, energizer bool) {
+	if p == nil || *g.livesOf(p) <= 0 || !g.CurrentMap.HasDot(p.X, p.Y) {
+		return false, false
+	}
+	energizer = g.CurrentMap.IsEnergizer(p.X, p.Y)
+	g.CurrentMap.EatDot(p.X, p.Y)
+	baseScore := dotScore
+	if energizer {
+		baseScore = energizerScore
+	}
+	*g.scoreOf(p) += g.scaledScore(baseScore)
+	return true, energizer
+}
+
+// bust costs the caught player a life and pauses the board before everyone
+// respawns. The game is over when no player has lives left.
+func (g *Game) bust(p *actors.Player) {
+	*g.livesOf(p)--
+	if len(g.Players()) == 0 {
+		g.GameOver = true
+		g.LifeLost = false
+		return
+	}
+	g.LifeLost = true
+	g.BustPaused = true
+	g.pendingRespawn = true
+	g.bustPauseLeft = g.BustPauseTicks
+}
+
+func (g *Game) scoreOf(p *actors.Player) *int {
+	if p != nil && p == g.Player2 {
+		return &g.Score2
+	}
+	return &g.Score
+}
+
+func (g *Game) livesOf(p *actors.Player) *int {
+	if p != nil && p == g.Player2 {
+		return &g.Lives2
+	}
+	return &g.Lives
+}
+
+// eatMonster scores a frightened monster for the player who caught it,
+// doubling the reward for every further monster caught on the same
+// energizer, and sends it home.
+func (g *Game) eatMonster(mo *actors.Monster, score *int) {
+	points := monsterScores[min(g.eatStreak, len(monsterScores)-1)]
+	g.eatStreak++
+	*score += g.scaledScore(points)
+	g.MonsterEaten = true
+	mo.Respawn()
+}
+
+func (g *Game) scaledScore(base int) int {
+	return int(float64(base) * g.CurrentSpeedModifier * g.ScoreMultiplier())
+}
+
+// ScoreMultiplier is the endless-mode bonus on every score, 1 in the first
+// cycle and in normal games.
+func (g *Game) ScoreMultiplier() float64 {
+	return 1 + endlessScoreStep*float64(g.Cycle)
+}
+
+// Render draws the board and a status line for terminal front-ends.
+func (g *Game) Render(w io.Writer) {
+	creatures := make([]maps.Creature, len(g.Monsters))
+	for i := range g.Monsters {
+		creatures[i] = &g.Monsters[i]
+	}
+	g.CurrentMap.Render(w, g.Player.X, g.Player.Y, creatures)
+
+	levelName := g.CurrentMap.Name
+	if levelName == "" {
+		levelName = fmt.Sprintf("Level %d", g.CurrentLevel+1)
+	}
+	if g.Endless {
+		levelName = fmt.Sprintf("%s | Cycle %d", levelName, g.Cycle+1)
+	}
+	fmt.Fprintf(w, "\r\n%s | Score: %d | Dots: %d | Lives: %s\033[K\r\n",
+		levelName, g.Score, g.CurrentMap.CountDots(), strings.Repeat("\033[31m♥\033[0m", g.Lives))
+	fmt.Fprint(w, "Controls: Arrows/WASD move, Q quit\033[K\r\n")
 
 	if g.GameOver {
-		fmt.Println("\n\033[31mGAME OVER!\033[0m")
+		fmt.Fprint(w, "\r\n\033[31mGAME OVER!\033[0m\033[K\r\n")
 	}
 	if g.Won {
-		fmt.Println("\n\033[32mYOU WON! All levels completed!\033[0m")
+		fmt.Fprint(w, "\r\n\033[32mYOU WON! All levels completed!\033[0m\033[K\r\n")
 	}
 }
 
@@ -333,51 +516,3 @@ func (g *Game) HandleInput(input string) {
 		g.GameOver = true
 	}
 }
-
-func RunGame(mapFile string) error {
-	mapsList, err := maps.LoadMapsFromFile(mapFile)
-	if err != nil {
-		return fmt.Errorf("failed to load maps: %v", err)
-	}
-
-	if len(mapsList) == 0 {
-		return fmt.Errorf("no maps found in file")
-	}
-
-	game := NewGame(mapsList, false)
-	if game == nil {
-		return fmt.Errorf("failed to create game")
-	}
-
-	// Game loop
-	ticker := time.NewTicker(200 * time.Millisecond)
-	defer ticker.Stop()
-
-	// Input channel
-	inputChan := make(chan string, 10)
-	go func() {
-		for {
-			var input string
-			fmt.Scanln(&input)
-			inputChan <- input
-		}
-	}()
-
-	for !game.GameOver && !game.Won {
-		select {
-		case <-ticker.C:
-			game.Update()
-			game.Render()
-		case input := <-inputChan:
-			game.HandleInput(input)
-		}
-	}
-
-	// Final render
-	game.Render()
-
-	// Wait a bit before exiting
-	time.Sleep(2 * time.Second)
-
-	return nil
-}
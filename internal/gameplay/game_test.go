@@ -1,8 +1,14 @@
 package gameplay
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/sjiamnocna/gopucha/internal/actors"
 	"github.com/sjiamnocna/gopucha/internal/maps"
 )
 
@@ -169,6 +175,283 @@ func TestDotScoringWithSpeedModifier(t *testing.T) {
 	}
 }
 
+func TestSeededGamesAreIdentical(t *testing.T) {
+	m, err := parseMap([]string{
+		"monsters: 3",
+		"OOOOOOOOOO",
+		"O--------O",
+		"O-OO-OOO-O",
+		"O--------O",
+		"O-OOO-OO-O",
+		"O--------O",
+		"OOOOOOOOOO",
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse map: %v", err)
+	}
+
+	inputs := map[int]actors.Direction{3: actors.Down, 9: actors.Left, 15: actors.Up, 22: actors.Right}
+	run := func() *Game {
+		game := NewGameWithSeed([]maps.Map{m}, false, 42)
+		for i := 0; i < 60 && !game.GameOver; i++ {
+			if d, ok := inputs[game.Tick]; ok {
+				game.Player.SetDirection(d)
+			}
+			game.Update()
+		}
+		return game
+	}
+
+	a, b := run(), run()
+	if a.Tick != b.Tick || a.Score != b.Score || a.Lives != b.Lives {
+		t.Fatalf("runs diverged: tick %d/%d score %d/%d lives %d/%d",
+			a.Tick, b.Tick, a.Score, b.Score, a.Lives, b.Lives)
+	}
+	if a.Player.X != b.Player.X || a.Player.Y != b.Player.Y {
+		t.Errorf("player diverged: (%d,%d) vs (%d,%d)", a.Player.X, a.Player.Y, b.Player.X, b.Player.Y)
+	}
+	if len(a.Monsters) != len(b.Monsters) {
+		t.Fatalf("monster count diverged: %d vs %d", len(a.Monsters), len(b.Monsters))
+	}
+	for i := range a.Monsters {
+		ma, mb := a.Monsters[i], b.Monsters[i]
+		if ma.X != mb.X || ma.Y != mb.Y || ma.Direction != mb.Direction {
+			t.Errorf("monster %d diverged: %+v vs %+v", i, a.Monsters[i], b.Monsters[i])
+		}
+	}
+
+	if m.CountDots() == a.CurrentMap.CountDots() {
+		t.Errorf("source map was not expected to change, game map was expected to")
+	}
+}
+
+func TestEndlessModeStartsNextCycle(t *testing.T) {
+	m, err := parseMap([]string{
+		"monsters: 1",
+		"OOOOOOOOOO",
+		"OP-------O",
+		"O-OO-OOO-O",
+		"O--------O",
+		"OOOOOOOOOO",
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse map: %v", err)
+	}
+	dots := m.CountDots()
+
+	game := NewGameWithSeed([]maps.Map{m, m}, false, 1)
+	game.Endless = true
+	game.CurrentMap.EatDot(2, 1)
+	game.AdvanceLevel()
+	game.AdvanceLevel()
+
+	if game.Won {
+		t.Fatalf("endless game was won after the last level")
+	}
+	if game.Cycle != 1 || game.CurrentLevel != 0 {
+		t.Fatalf("cycle %d level %d, want cycle 1 level 0", game.Cycle, game.CurrentLevel)
+	}
+	// The player's start had no dot, so nothing was eaten yet.
+	if got := game.CurrentMap.CountDots(); got != dots {
+		t.Errorf("next cycle has %d dots, want a fresh level with %d", got, dots)
+	}
+	if len(game.Monsters) != 2 {
+		t.Errorf("next cycle has %d monsters, want 2", len(game.Monsters))
+	}
+	if game.CurrentSpeedModifier != 1.1 {
+		t.Errorf("CurrentSpeedModifier = %v, want 1.1", game.CurrentSpeedModifier)
+	}
+	if game.ScoreMultiplier() != 1.5 {
+		t.Errorf("ScoreMultiplier() = %v, want 1.5", game.ScoreMultiplier())
+	}
+
+	normal := NewGameWithSeed([]maps.Map{m}, false, 1)
+	normal.AdvanceLevel()
+	if !normal.Won || normal.Cycle != 0 {
+		t.Errorf("normal game after the last level: won %v cycle %d", normal.Won, normal.Cycle)
+	}
+}
+
+func TestBustPauseCountsTicks(t *testing.T) {
+	m, err := parseMap([]string{
+		"OOOOOO",
+		"OP--MO",
+		"OOOOOO",
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse map: %v", err)
+	}
+
+	game := NewGameWithSeed([]maps.Map{m}, false, 1)
+	game.BustPauseTicks = 3
+	game.Player.SetDirection(actors.Right)
+	for i := 0; i < 5 && !game.LifeLost; i++ {
+		game.Update()
+	}
+	if !game.LifeLost {
+		t.Fatalf("expected the player to be caught")
+	}
+
+	for i := 0; i < game.BustPauseTicks; i++ {
+		game.Update()
+		if !game.BustPaused {
+			t.Fatalf("tick %d after bust: expected pause", i+1)
+		}
+	}
+	game.Update()
+	if game.BustPaused {
+		t.Errorf("expected pause to end after %d ticks", game.BustPauseTicks)
+	}
+}
+
+func TestSplitsCountTicksPerLevel(t *testing.T) {
+	m, err := parseMap([]string{
+		"OOOOOO",
+		"OP---O",
+		"OOOOOO",
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse map: %v", err)
+	}
+
+	game := NewGameWithSeed([]maps.Map{m, m}, true, 1)
+	for level := 0; level < 2; level++ {
+		game.Player.SetDirection(actors.Right)
+		for i := 0; i < 10 && !game.LevelCompleted; i++ {
+			game.Update()
+		}
+		if !game.LevelCompleted {
+			t.Fatalf("level %d was not cleared", level+1)
+		}
+		// Ticks after clearing must not add another split.
+		game.Update()
+		game.AdvanceLevel()
+	}
+
+	if want := []int{3, 3}; !reflect.DeepEqual(game.Splits, want) {
+		t.Errorf("Splits = %v, want %v", game.Splits, want)
+	}
+}
+
+func TestEnergizerLetsPlayerEatMonsters(t *testing.T) {
+	m, err := parseMap([]string{
+		"OOOOOOOOOOO",
+		"OP*-------O",
+		"O-OOOOOOO-O",
+		"O---------O",
+		"OOOOOOOOOOO",
+		"monsters: 2",
+		"monsterStarts: 9,1; 9,3",
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse map: %v", err)
+	}
+
+	game := NewGameWithSeed([]maps.Map{m}, false, 1)
+	game.Player.SetDirection(actors.Right)
+	game.Update()
+	if !game.EnergizerEaten || game.Score != energizerScore {
+		t.Fatalf("expected the energizer to be eaten for %d points, score %d", energizerScore, game.Score)
+	}
+	for i, mo := range game.Monsters {
+		if !mo.IsFrightened() {
+			t.Errorf("monster %d not frightened", i)
+		}
+	}
+
+	// Put both monsters in the player's way to eat them one after another.
+	game.Monsters[0].X, game.Monsters[0].Y = 3, 1
+	game.Monsters[1].X, game.Monsters[1].Y = 4, 1
+	score := game.Score
+	game.Update()
+	if !game.MonsterEaten || game.Lives != 4 {
+		t.Fatalf("expected the first monster eaten without losing a life (lives %d)", game.Lives)
+	}
+	if x, y := game.Monsters[0].X, game.Monsters[0].Y; x != 9 || y != 1 {
+		t.Errorf("eaten monster at (%d,%d), want home (9,1)", x, y)
+	}
+	game.Update()
+	if got := game.Score - score; got != 2*dotScore+monsterScores[0]+monsterScores[1] {
+		t.Errorf("scored %d for two dots and two monsters", got)
+	}
+
+	for game.Monsters[1].IsFrightened() {
+		game.Update()
+	}
+	if game.Tick > 2+game.FrightenedTicks {
+		t.Errorf("monsters still frightened at tick %d", game.Tick)
+	}
+}
+
+func TestCoOpPlayersScoreAndLoseLivesSeparately(t *testing.T) {
+	m, err := parseMap([]string{
+		"player2Start: 7,1",
+		"OOOOOOOOO",
+		"OP-----.O",
+		"O-OOOOO-O",
+		"O---M---O",
+		"OOOOOOOOO",
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse map: %v", err)
+	}
+
+	game := NewCoOpGame([]maps.Map{m}, false, 1)
+	if game.Player2 == nil || game.Player2.X != 7 || game.Player2.Y != 1 {
+		t.Fatalf("second player at %+v, want (7,1)", game.Player2)
+	}
+
+	// Player two walks into a monster coming the other way.
+	game.Lives2 = 1
+	game.Monsters[0].X, game.Monsters[0].Y = 6, 1
+	game.Monsters[0].Direction = actors.Right
+	game.Player.SetDirection(actors.Right)
+	game.Player2.SetDirection(actors.Left)
+	game.Update()
+
+	// Both ate a dot on the way, each for their own score.
+	if game.Score != dotScore || game.Score2 != dotScore {
+		t.Errorf("scores %d/%d, want %d each", game.Score, game.Score2, dotScore)
+	}
+	if game.Lives != startLives || game.Lives2 != 0 {
+		t.Errorf("lives %d/%d, want %d/0", game.Lives, game.Lives2, startLives)
+	}
+	if game.GameOver || !game.LifeLost {
+		t.Fatalf("expected a bust with player one still in the game")
+	}
+	if players := game.Players(); len(players) != 1 || players[0] != game.Player {
+		t.Errorf("Players() = %v, want only player one", players)
+	}
+
+	// With player two out, the last life of player one ends the game.
+	game.Lives = 1
+	game.bust(game.Player)
+	if !game.GameOver {
+		t.Errorf("game not over after both players lost their lives")
+	}
+}
+
+// parseMap loads a single level through the public loader.
+func parseMap(lines []string) (maps.Map, error) {
+	tmpFile, err := os.CreateTemp("", "test_map_*.txt")
+	if err != nil {
+		return maps.Map{}, err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(strings.Join(lines, "\n")); err != nil {
+		tmpFile.Close()
+		return maps.Map{}, err
+	}
+	tmpFile.Close()
+
+	mapsList, err := maps.LoadMapsFromFile(tmpFile.Name())
+	if err != nil {
+		return maps.Map{}, err
+	}
+	return mapsList[0], nil
+}
+
 // Helper function to format float for test strings
 func formatFloat(f float64) string {
 	switch f {
@@ -184,3 +467,124 @@ func formatFloat(f float64) string {
 		return "1.0"
 	}
 }
+
+func TestSnapshotRestoreContinuesIdentically(t *testing.T) {
+	lines := []string{
+		"monsters: 3",
+		"monsterStarts: 8,1; 1,5; 8,5",
+		"monsterBrains: wanderer; patrol 1,5 8,5 8,3; chaser",
+		"OOOOOOOOOO",
+		"O--------O",
+		"O-OO-OOO-O",
+		"O---*----O",
+		"O-OOO-OO-O",
+		"O--------O",
+		"OOOOOOOOOO",
+	}
+	mapFile := filepath.Join(t.TempDir(), "snap.txt")
+	if err := os.WriteFile(mapFile, []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+		t.Fatalf("Failed to write map: %v", err)
+	}
+	mapsList, err := maps.LoadMapsFromFile(mapFile)
+	if err != nil {
+		t.Fatalf("Failed to load map: %v", err)
+	}
+
+	inputs := map[int]actors.Direction{3: actors.Down, 9: actors.Right, 15: actors.Up, 22: actors.Left, 30: actors.Down}
+	step := func(g *Game) {
+		if d, ok := inputs[g.Tick]; ok {
+			g.Player.SetDirection(d)
+		}
+		g.Update()
+	}
+
+	game := NewGameWithSeed(mapsList, false, 7)
+	for i := 0; i < 12; i++ {
+		step(game)
+	}
+	snap, err := game.Snapshot(mapFile)
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err := snap.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	read, err := ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatalf("ReadSnapshot() error = %v", err)
+	}
+	loaded, err := read.LoadMaps("")
+	if err != nil {
+		t.Fatalf("LoadMaps() error = %v", err)
+	}
+	restored, err := Restore(loaded, read)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	for i := 0; i < 40 && !game.GameOver; i++ {
+		step(game)
+		step(restored)
+	}
+	if game.Tick != restored.Tick || game.Score != restored.Score || game.Lives != restored.Lives {
+		t.Fatalf("games diverged: tick %d/%d score %d/%d lives %d/%d",
+			game.Tick, restored.Tick, game.Score, restored.Score, game.Lives, restored.Lives)
+	}
+	if game.Player.X != restored.Player.X || game.Player.Y != restored.Player.Y {
+		t.Errorf("player diverged: (%d,%d) vs (%d,%d)", game.Player.X, game.Player.Y, restored.Player.X, restored.Player.Y)
+	}
+	for i := range game.Monsters {
+		a, b := game.Monsters[i], restored.Monsters[i]
+		if a.X != b.X || a.Y != b.Y || a.Direction != b.Direction || a.Frightened != b.Frightened {
+			t.Errorf("monster %d diverged: %+v vs %+v", i, a, b)
+		}
+	}
+	if game.CurrentMap.CountDots() != restored.CurrentMap.CountDots() {
+		t.Errorf("dots diverged: %d vs %d", game.CurrentMap.CountDots(), restored.CurrentMap.CountDots())
+	}
+
+	// A changed map pack must not be continued.
+	if err := os.WriteFile(mapFile, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write map: %v", err)
+	}
+	if _, err := read.LoadMaps(""); err == nil {
+		t.Errorf("expected LoadMaps to refuse an edited map file")
+	}
+}
+
+func TestVersusHunterScoresCatches(t *testing.T) {
+	m, err := parseMap([]string{
+		"monsters: 0",
+		"OOOOOOO",
+		"OP----O",
+		"O-OOO-O",
+		"O-----O",
+		"OOOOOOO",
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse map: %v", err)
+	}
+
+	game := NewVersusGame([]maps.Map{m}, 1, false)
+	if len(game.Monsters) != 1 || game.Monsters[0].Brain != game.Hunter {
+		t.Fatalf("versus level without monsters should get the human one, got %+v", game.Monsters)
+	}
+
+	// The monster player drives into Pampuch head-on.
+	game.Monsters[0].X, game.Monsters[0].Y = 3, 1
+	game.Monsters[0].Direction = actors.Left
+	game.Hunter.SetDirection(actors.Left)
+	game.Player.SetDirection(actors.Right)
+	game.Update()
+
+	if !game.LifeLost || game.Lives != startLives-1 {
+		t.Fatalf("expected a bust, lives %d", game.Lives)
+	}
+	if game.Catches != 1 || game.HunterScore != catchScore {
+		t.Errorf("catches %d, hunter score %d, want 1 and %d", game.Catches, game.HunterScore, catchScore)
+	}
+	if _, err := game.Snapshot("unused.txt"); err == nil {
+		t.Error("versus game should not be saveable")
+	}
+}
@@ -0,0 +1,457 @@
+package gameplay
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sjiamnocna/gopucha/internal/actors"
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+// Snapshot freezes the game for Restore. mapFile is the file the game's
+// levels were loaded from; its hash guards against continuing on a map
+// pack that changed in the meantime.
+func (g *Game) Snapshot(mapFile string) (*Snapshot, error) {
+	if g.TwoPlayers() {
+		return nil, fmt.Errorf("two-player games cannot be saved")
+	}
+	hash, err := maps.HashFile(mapFile)
+	if err != nil {
+		return nil, err
+	}
+	rngState, err := g.src.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Snapshot{
+		Version:         snapshotVersion,
+		MapFile:         mapFile,
+		MapHash:         hash,
+		Level:           g.CurrentLevel,
+		Tick:            g.Tick,
+		Score:           g.Score,
+		Lives:           g.Lives,
+		Seed:            g.Seed,
+		SpeedModifier:   g.CurrentSpeedModifier,
+		DisableMonsters: g.DisableMonsters,
+		BustPauseTicks:  g.BustPauseTicks,
+		FrightenedTicks: g.FrightenedTicks,
+		BustPauseLeft:   g.bustPauseLeft,
+		PendingRespawn:  g.pendingRespawn,
+		EatStreak:       g.eatStreak,
+		Endless:         g.Endless,
+		Cycle:           g.Cycle,
+		RNG:             rngState,
+		Player: PlayerState{
+			X:         g.Player.X,
+			Y:         g.Player.Y,
+			Direction: g.Player.Direction,
+			Desired:   g.Player.Desired,
+		},
+	}
+
+	m := g.CurrentMap
+	for y := 0; y < m.Height; y++ {
+		row := make([]byte, m.Width)
+		for x := range row {
+			switch m.Cells[y][x] {
+			case maps.Dot:
+				row[x] = '-'
+			case maps.Energizer:
+				row[x] = '*'
+			default:
+				row[x] = '.'
+			}
+		}
+		s.Dots = append(s.Dots, string(row))
+	}
+
+	for _, mo := range g.Monsters {
+		ms := MonsterState{
+			X:          mo.X,
+			Y:          mo.Y,
+			Direction:  mo.Direction,
+			Home:       mo.Home,
+			Frightened: mo.Frightened,
+		}
+		ms.Brain, ms.PatrolNext = brainSpecOf(mo.Brain)
+		s.Monsters = append(s.Monsters, ms)
+	}
+	return s, nil
+}
+
+// brainSpecOf names a brain the way a map's monsterBrains key would.
+func brainSpecOf(b actors.MonsterBrain) (maps.BrainSpec, int) {
+	switch brain := b.(type) {
+	case actors.ChaserBrain:
+		return maps.BrainSpec{Name: maps.BrainChaser}, 0
+	case actors.AmbusherBrain:
+		return maps.BrainSpec{Name: maps.BrainAmbusher}, 0
+	case actors.WandererBrain:
+		return maps.BrainSpec{Name: maps.BrainWanderer}, 0
+	case *actors.PatrolBrain:
+		route := append([]maps.StartPos(nil), brain.Route...)
+		return maps.BrainSpec{Name: maps.BrainPatrol, Route: route}, brain.Next
+	}
+	return maps.BrainSpec{Name: maps.BrainClassic}, 0
+}
+
+// Restore rebuilds a game from a snapshot. mapsList should come from
+// Snapshot.LoadMaps.
+func Restore(mapsList []maps.Map, s *Snapshot) (*Game, error) {
+	if s.Level < 0 || s.Level >= len(mapsList) {
+		return nil, fmt.Errorf("saved level %d out of range (file has %d levels)", s.Level+1, len(mapsList))
+	}
+	src := &rand.PCG{}
+	if err := src.UnmarshalBinary(s.RNG); err != nil {
+		return nil, fmt.Errorf("invalid rng state: %v", err)
+	}
+
+	cloned := cloneMaps(mapsList)
+	g := &Game{
+		Maps:                 cloned,
+		CurrentLevel:         s.Level,
+		CurrentMap:           &cloned[s.Level],
+		CurrentSpeedModifier: s.SpeedModifier,
+		Score:                s.Score,
+		Lives:                s.Lives,
+		DisableMonsters:      s.DisableMonsters,
+		Seed:                 s.Seed,
+		Tick:                 s.Tick,
+		BustPauseTicks:       s.BustPauseTicks,
+		FrightenedTicks:      s.FrightenedTicks,
+		bustPauseLeft:        s.BustPauseLeft,
+		pendingRespawn:       s.PendingRespawn,
+		eatStreak:            s.EatStreak,
+		levelStartTick:       s.Tick, // Splits are not saved, the level clock restarts
+		Endless:              s.Endless,
+		Cycle:                s.Cycle,
+		levels:               cloneMaps(mapsList),
+		rng:                  rand.New(src),
+		src:                  src,
+	}
+
+	m := g.CurrentMap
+	if len(s.Dots) != m.Height {
+		return nil, fmt.Errorf("saved level has %d rows, map has %d", len(s.Dots), m.Height)
+	}
+	for y, row := range s.Dots {
+		if len(row) != m.Width {
+			return nil, fmt.Errorf("saved row %d has %d cells, map has %d", y+1, len(row), m.Width)
+		}
+		for x := 0; x < m.Width; x++ {
+			if m.IsWall(x, y) {
+				continue
+			}
+			switch row[x] {
+			case '-':
+				m.Cells[y][x] = maps.Dot
+			case '*':
+				m.Cells[y][x] = maps.Energizer
+			default:
+				m.Cells[y][x] = maps.Empty
+			}
+		}
+	}
+
+	if m.IsWall(s.Player.X, s.Player.Y) {
+		return nil, fmt.Errorf("saved player position %d,%d is not walkable", s.Player.X, s.Player.Y)
+	}
+	g.Player = actors.NewPlayer(s.Player.X, s.Player.Y)
+	g.Player.Direction = s.Player.Direction
+	g.Player.Desired = s.Player.Desired
+
+	for i, ms := range s.Monsters {
+		if m.IsWall(ms.X, ms.Y) {
+			return nil, fmt.Errorf("saved monster %d position %d,%d is not walkable", i+1, ms.X, ms.Y)
+		}
+		brain, err := actors.NewBrain(ms.Brain)
+		if err != nil {
+			return nil, fmt.Errorf("saved monster %d: %v", i+1, err)
+		}
+		if patrol, ok := brain.(*actors.PatrolBrain); ok {
+			patrol.Next = ms.PatrolNext % len(patrol.Route)
+		}
+		mo := actors.NewMonster(ms.X, ms.Y, ms.Direction)
+		mo.Home = ms.Home
+		mo.Frightened = ms.Frightened
+		mo.Brain = brain
+		g.Monsters = append(g.Monsters, *mo)
+	}
+	return g, nil
+}
+
+// LoadMaps loads the saved map file, or mapFile when it is not empty, and
+// refuses it if the contents differ from when the game was saved.
+func (s *Snapshot) LoadMaps(mapFile string) ([]maps.Map, error) {
+	if mapFile == "" {
+		mapFile = s.MapFile
+	}
+	hash, err := maps.HashFile(mapFile)
+	if err != nil {
+		return nil, err
+	}
+	if hash != s.MapHash {
+		return nil, fmt.Errorf("map file %s changed since the game was saved", mapFile)
+	}
+	return maps.LoadMapsFromFile(mapFile)
+}
+
+func LoadSnapshot(filename string) (*Snapshot, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	s, err := ReadSnapshot(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return s, nil
+}
+
+func (s *Snapshot) Save(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := s.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (s *Snapshot) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%s %d\n", snapshotMagic, snapshotVersion)
+	fmt.Fprintf(bw, "map: %s\n", s.MapFile)
+	fmt.Fprintf(bw, "mapHash: %s\n", s.MapHash)
+	fmt.Fprintf(bw, "level: %d\n", s.Level)
+	fmt.Fprintf(bw, "tick: %d\n", s.Tick)
+	fmt.Fprintf(bw, "score: %d\n", s.Score)
+	fmt.Fprintf(bw, "lives: %d\n", s.Lives)
+	fmt.Fprintf(bw, "seed: %d\n", s.Seed)
+	fmt.Fprintf(bw, "speedModifier: %s\n", strconv.FormatFloat(s.SpeedModifier, 'g', -1, 64))
+	fmt.Fprintf(bw, "noMonsters: %t\n", s.DisableMonsters)
+	fmt.Fprintf(bw, "bustPauseTicks: %d\n", s.BustPauseTicks)
+	fmt.Fprintf(bw, "frightenedTicks: %d\n", s.FrightenedTicks)
+	fmt.Fprintf(bw, "bustPauseLeft: %d\n", s.BustPauseLeft)
+	fmt.Fprintf(bw, "pendingRespawn: %t\n", s.PendingRespawn)
+	fmt.Fprintf(bw, "eatStreak: %d\n", s.EatStreak)
+	fmt.Fprintf(bw, "endless: %t\n", s.Endless)
+	fmt.Fprintf(bw, "cycle: %d\n", s.Cycle)
+	fmt.Fprintf(bw, "rng: %s\n", hex.EncodeToString(s.RNG))
+	fmt.Fprintf(bw, "player: %d %d %s %s\n", s.Player.X, s.Player.Y, s.Player.Direction, s.Player.Desired)
+	for _, ms := range s.Monsters {
+		fmt.Fprintf(bw, "monster: %d %d %s %d,%d %d %s", ms.X, ms.Y, ms.Direction, ms.Home.X, ms.Home.Y, ms.Frightened, ms.Brain.Name)
+		if ms.Brain.Name == maps.BrainPatrol {
+			fmt.Fprintf(bw, " %d", ms.PatrolNext)
+			for _, p := range ms.Brain.Route {
+				fmt.Fprintf(bw, " %d,%d", p.X, p.Y)
+			}
+		}
+		fmt.Fprintln(bw)
+	}
+	fmt.Fprintln(bw, snapshotGrid)
+	for _, row := range s.Dots {
+		fmt.Fprintln(bw, row)
+	}
+	return bw.Flush()
+}
+
+func ReadSnapshot(rd io.Reader) (*Snapshot, error) {
+	scanner := bufio.NewScanner(rd)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("empty save file")
+	}
+
+	header := strings.Fields(scanner.Text())
+	if len(header) != 2 || header[0] != snapshotMagic {
+		return nil, fmt.Errorf("not a save file")
+	}
+	version, err := strconv.Atoi(header[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid save version %q", header[1])
+	}
+	if version > snapshotVersion {
+		return nil, fmt.Errorf("save version %d is newer than supported version %d", version, snapshotVersion)
+	}
+
+	s := &Snapshot{Version: version}
+	lineNo := 1
+	inGrid := false
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if inGrid {
+			s.Dots = append(s.Dots, line)
+			continue
+		}
+		if line == snapshotGrid {
+			inGrid = true
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", lineNo)
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "map":
+			s.MapFile = value
+		case "maphash":
+			s.MapHash = value
+		case "level":
+			s.Level, err = strconv.Atoi(value)
+		case "tick":
+			s.Tick, err = strconv.Atoi(value)
+		case "score":
+			s.Score, err = strconv.Atoi(value)
+		case "lives":
+			s.Lives, err = strconv.Atoi(value)
+		case "seed":
+			s.Seed, err = strconv.ParseInt(value, 10, 64)
+		case "speedmodifier":
+			s.SpeedModifier, err = strconv.ParseFloat(value, 64)
+		case "nomonsters":
+			s.DisableMonsters, err = strconv.ParseBool(value)
+		case "bustpauseticks":
+			s.BustPauseTicks, err = strconv.Atoi(value)
+		case "frightenedticks":
+			s.FrightenedTicks, err = strconv.Atoi(value)
+		case "bustpauseleft":
+			s.BustPauseLeft, err = strconv.Atoi(value)
+		case "pendingrespawn":
+			s.PendingRespawn, err = strconv.ParseBool(value)
+		case "eatstreak":
+			s.EatStreak, err = strconv.Atoi(value)
+		case "endless":
+			s.Endless, err = strconv.ParseBool(value)
+		case "cycle":
+			s.Cycle, err = strconv.Atoi(value)
+		case "rng":
+			s.RNG, err = hex.DecodeString(value)
+		case "player":
+			s.Player, err = parsePlayerState(value)
+		case "monster":
+			var ms MonsterState
+			ms, err = parseMonsterState(value)
+			s.Monsters = append(s.Monsters, ms)
+		default:
+			// Unknown keys from newer writers are ignored.
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid %s: %q", lineNo, strings.TrimSpace(key), value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if s.MapHash == "" {
+		return nil, fmt.Errorf("save file has no mapHash")
+	}
+	if len(s.Dots) == 0 {
+		return nil, fmt.Errorf("save file has no level grid")
+	}
+	return s, nil
+}
+
+// parsePlayerState reads "x y direction desired".
+func parsePlayerState(value string) (PlayerState, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 4 {
+		return PlayerState{}, fmt.Errorf("expected 4 fields")
+	}
+	var p PlayerState
+	var err error
+	if p.X, p.Y, err = parseXY(fields[0], fields[1]); err != nil {
+		return PlayerState{}, err
+	}
+	if p.Direction, err = actors.ParseDirection(fields[2]); err != nil {
+		return PlayerState{}, err
+	}
+	if p.Desired, err = actors.ParseDirection(fields[3]); err != nil {
+		return PlayerState{}, err
+	}
+	return p, nil
+}
+
+// parseMonsterState reads "x y direction homeX,homeY frightened brain",
+// followed by "next x,y x,y ..." for patrols.
+func parseMonsterState(value string) (MonsterState, error) {
+	fields := strings.Fields(value)
+	if len(fields) < 6 {
+		return MonsterState{}, fmt.Errorf("expected at least 6 fields")
+	}
+	var ms MonsterState
+	var err error
+	if ms.X, ms.Y, err = parseXY(fields[0], fields[1]); err != nil {
+		return MonsterState{}, err
+	}
+	if ms.Direction, err = actors.ParseDirection(fields[2]); err != nil {
+		return MonsterState{}, err
+	}
+	if ms.Home, err = parsePos(fields[3]); err != nil {
+		return MonsterState{}, err
+	}
+	if ms.Frightened, err = strconv.Atoi(fields[4]); err != nil {
+		return MonsterState{}, err
+	}
+	ms.Brain.Name = fields[5]
+	if ms.Brain.Name != maps.BrainPatrol {
+		return ms, nil
+	}
+	if len(fields) < 8 {
+		return MonsterState{}, fmt.Errorf("patrol needs a waypoint index and a route")
+	}
+	if ms.PatrolNext, err = strconv.Atoi(fields[6]); err != nil {
+		return MonsterState{}, err
+	}
+	for _, f := range fields[7:] {
+		p, err := parsePos(f)
+		if err != nil {
+			return MonsterState{}, err
+		}
+		ms.Brain.Route = append(ms.Brain.Route, p)
+	}
+	return ms, nil
+}
+
+func parseXY(xs, ys string) (int, int, error) {
+	x, err := strconv.Atoi(xs)
+	if err != nil {
+		return 0, 0, err
+	}
+	y, err := strconv.Atoi(ys)
+	if err != nil {
+		return 0, 0, err
+	}
+	return x, y, nil
+}
+
+func parsePos(s string) (maps.StartPos, error) {
+	xs, ys, found := strings.Cut(s, ",")
+	if !found {
+		return maps.StartPos{}, fmt.Errorf("expected x,y")
+	}
+	x, y, err := parseXY(xs, ys)
+	return maps.StartPos{X: x, Y: y}, err
+}
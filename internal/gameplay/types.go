@@ -1,7 +1,7 @@
 package gameplay
 
 import (
-	"time"
+	"math/rand/v2"
 
 	"github.com/sjiamnocna/gopucha/internal/actors"
 	"github.com/sjiamnocna/gopucha/internal/maps"
@@ -12,17 +12,84 @@ type Game struct {
 	Maps                 []maps.Map
 	CurrentLevel         int
 	Player               *actors.Player
+	Player2              *actors.Player // Second player in co-op, nil otherwise
 	Monsters             []actors.Monster
 	GameOver             bool
 	Won                  bool
 	Score                int
 	Lives                int
+	Score2               int // Player two's score in co-op
+	Lives2               int // Player two's lives in co-op
+	CoOp                 bool
+	Versus               bool               // A second player steers the first monster
+	Hunter               *actors.HumanBrain // Brain of that monster in versus, nil otherwise
+	Catches              int                // Times the human monster caught the player
+	HunterScore          int                // Monster player's score in versus
 	LifeLost             bool
 	DisableMonsters      bool
-	DotEaten             bool
+	DotEaten             bool // Player one ate this tick
+	DotEaten2            bool // Player two ate this tick
 	CurrentSpeedModifier float64
 	LevelCompleted       bool
 	BustPaused           bool
-	bustPauseUntil       time.Time
+	Seed                 int64
+	Tick                 int  // Number of Update calls so far
+	BustPauseTicks       int  // Ticks to hold the board still after a bust
+	FrightenedTicks      int  // Ticks monsters flee after an energizer
+	EnergizerEaten       bool // Player one's meal was an energizer
+	EnergizerEaten2      bool // Player two's meal was an energizer
+	MonsterEaten         bool
+	Endless              bool  // Start over with the first level instead of winning
+	Cycle                int   // Times an endless game went through the whole pack
+	Splits               []int // Ticks spent on each cleared level, in order
+	levelStartTick       int
+	bustPauseLeft        int
+	eatStreak            int // Monsters eaten on the current energizer
 	pendingRespawn       bool
+	levels               []maps.Map // Untouched levels for the next endless cycle
+	rng                  *rand.Rand
+	src                  *rand.PCG // rng's state, kept for snapshots
+}
+
+// Snapshot is a game frozen between two ticks. Levels are loaded from the
+// map file again, so only the current level's remaining dots are stored.
+type Snapshot struct {
+	Version         int
+	MapFile         string
+	MapHash         string
+	Level           int
+	Tick            int
+	Score           int
+	Lives           int
+	Seed            int64
+	SpeedModifier   float64
+	DisableMonsters bool
+	BustPauseTicks  int
+	FrightenedTicks int
+	BustPauseLeft   int
+	PendingRespawn  bool
+	EatStreak       int
+	Endless         bool
+	Cycle           int
+	RNG             []byte   // Marshalled PCG state
+	Dots            []string // One row per map row: '-' dot, '*' energizer, '.' anything else
+	Player          PlayerState
+	Monsters        []MonsterState
+}
+
+type PlayerState struct {
+	X         int
+	Y         int
+	Direction actors.Direction
+	Desired   actors.Direction
+}
+
+type MonsterState struct {
+	X          int
+	Y          int
+	Direction  actors.Direction
+	Home       maps.StartPos
+	Frightened int
+	Brain      maps.BrainSpec
+	PatrolNext int // Next waypoint of a patrol brain
 }
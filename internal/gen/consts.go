@@ -0,0 +1,13 @@
+package gen
+
+const (
+	DefaultWidth    = 24
+	DefaultHeight   = 16
+	DefaultDensity  = 0.3
+	DefaultMonsters = 2
+
+	// Smallest grid with room for a loop inside the border walls.
+	minSize = 5
+	// Walls cover at most this share of the inner cells.
+	maxDensity = 0.6
+)
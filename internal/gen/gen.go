@@ -0,0 +1,274 @@
+package gen
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+// Generate builds one level. Walls are mirrored left to right, every open
+// cell is reachable and no corridor is a dead end or the only way into a
+// part of the maze, so nothing can be trapped behind a single exit. The
+// density is a target: walls stop being added once no more fit.
+func Generate(cfg Config) (maps.Map, error) {
+	if cfg.Width < minSize || cfg.Height < minSize {
+		return maps.Map{}, fmt.Errorf("level must be at least %dx%d, got %dx%d", minSize, minSize, cfg.Width, cfg.Height)
+	}
+	if cfg.Density < 0 || cfg.Density > maxDensity {
+		return maps.Map{}, fmt.Errorf("invalid density: %v (must be between 0 and %v)", cfg.Density, maxDensity)
+	}
+	if cfg.Monsters < 0 || cfg.Monsters > cfg.Width-2 {
+		return maps.Map{}, fmt.Errorf("invalid monsters count: %d (must be between 0 and %d)", cfg.Monsters, cfg.Width-2)
+	}
+
+	b := newBuilder(cfg)
+	player, monsters := b.placeStarts()
+	b.addWalls(rand.New(rand.NewPCG(uint64(cfg.Seed), 0)))
+
+	m := b.toMap(player, monsters)
+	if err := maps.Validate(&m); err != nil {
+		return maps.Map{}, fmt.Errorf("generated level is invalid: %v", err)
+	}
+	return m, nil
+}
+
+// GenerateLevels builds count levels of the same size, level i from
+// cfg.Seed+i.
+func GenerateLevels(cfg Config, count int) ([]maps.Map, error) {
+	levels := make([]maps.Map, 0, count)
+	for i := 0; i < count; i++ {
+		levelCfg := cfg
+		levelCfg.Seed = cfg.Seed + int64(i)
+		m, err := Generate(levelCfg)
+		if err != nil {
+			return nil, fmt.Errorf("level %d: %v", i+1, err)
+		}
+		levels = append(levels, m)
+	}
+	return levels, nil
+}
+
+func newBuilder(cfg Config) *builder {
+	b := &builder{cfg: cfg, reserved: make(map[[2]int]bool)}
+	b.wall = make([][]bool, cfg.Height)
+	for y := range b.wall {
+		b.wall[y] = make([]bool, cfg.Width)
+		for x := range b.wall[y] {
+			b.wall[y][x] = x == 0 || y == 0 || x == cfg.Width-1 || y == cfg.Height-1
+		}
+	}
+	return b
+}
+
+// placeStarts puts the player in the middle of the bottom row and the
+// monsters from the middle of the top row outwards.
+func (b *builder) placeStarts() (maps.StartPos, []maps.StartPos) {
+	w, h := b.cfg.Width, b.cfg.Height
+	player := maps.StartPos{X: w / 2, Y: h - 2}
+	b.reserved[[2]int{player.X, player.Y}] = true
+
+	var monsters []maps.StartPos
+	for left := (w - 1) / 2; left >= 1 && len(monsters) < b.cfg.Monsters; left-- {
+		monsters = append(monsters, maps.StartPos{X: left, Y: 1})
+		if right := w - 1 - left; right != left && len(monsters) < b.cfg.Monsters {
+			monsters = append(monsters, maps.StartPos{X: right, Y: 1})
+		}
+	}
+	// Row order, so the starts can be written as grid letters.
+	sort.Slice(monsters, func(i, j int) bool { return monsters[i].X < monsters[j].X })
+	for _, pos := range monsters {
+		b.reserved[[2]int{pos.X, pos.Y}] = true
+	}
+	return player, monsters
+}
+
+// addWalls tries the inner cells in random order and keeps each wall, with
+// its mirror images, only while the open cells stay sound.
+func (b *builder) addWalls(rng *rand.Rand) {
+	w, h := b.cfg.Width, b.cfg.Height
+	target := int(b.cfg.Density*float64((w-2)*(h-2)) + 0.5)
+
+	var candidates [][2]int
+	for y := 1; y < h-1; y++ {
+		if b.cfg.MirrorVertical && y > h-1-y {
+			break
+		}
+		for x := 1; x <= w-1-x; x++ {
+			candidates = append(candidates, [2]int{x, y})
+		}
+	}
+	rng.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+
+	walls := 0
+	for _, c := range candidates {
+		if walls >= target {
+			break
+		}
+		cells := b.mirrored(c[0], c[1])
+		free := true
+		for _, cell := range cells {
+			free = free && !b.reserved[cell]
+		}
+		if !free {
+			continue
+		}
+		for _, cell := range cells {
+			b.wall[cell[1]][cell[0]] = true
+		}
+		if !b.sound() {
+			for _, cell := range cells {
+				b.wall[cell[1]][cell[0]] = false
+			}
+			continue
+		}
+		walls += len(cells)
+	}
+}
+
+// mirrored returns (x,y) and its distinct mirror images.
+func (b *builder) mirrored(x, y int) [][2]int {
+	w, h := b.cfg.Width, b.cfg.Height
+	cells := [][2]int{{x, y}}
+	add := func(cell [2]int) {
+		for _, c := range cells {
+			if c == cell {
+				return
+			}
+		}
+		cells = append(cells, cell)
+	}
+	add([2]int{w - 1 - x, y})
+	if b.cfg.MirrorVertical {
+		add([2]int{x, h - 1 - y})
+		add([2]int{w - 1 - x, h - 1 - y})
+	}
+	return cells
+}
+
+// sound reports whether the open cells are connected and no step between
+// two of them is a bridge, i.e. the only link between two parts of the
+// maze. That also rules out dead ends, whose last step is always a bridge.
+func (b *builder) sound() bool {
+	w, h := b.cfg.Width, b.cfg.Height
+	order := make([][]int, h)
+	for y := range order {
+		order[y] = make([]int, w)
+	}
+
+	open := 0
+	startX, startY := -1, -1
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if !b.wall[y][x] {
+				open++
+				if startX == -1 {
+					startX, startY = x, y
+				}
+			}
+		}
+	}
+	if open == 0 {
+		return false
+	}
+
+	visited := 0
+	bridge := false
+	// lowlink returns the earliest visit order reachable from (x,y)'s
+	// subtree without going back over the step it was entered by.
+	var lowlink func(x, y, fromX, fromY int) int
+	lowlink = func(x, y, fromX, fromY int) int {
+		visited++
+		order[y][x] = visited
+		low := visited
+		for _, d := range [][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+			nx, ny := x+d[0], y+d[1]
+			if b.wall[ny][nx] || (nx == fromX && ny == fromY) {
+				continue
+			}
+			if order[ny][nx] != 0 {
+				low = min(low, order[ny][nx])
+				continue
+			}
+			childLow := lowlink(nx, ny, x, y)
+			if childLow > order[y][x] {
+				bridge = true
+			}
+			low = min(low, childLow)
+		}
+		return low
+	}
+	lowlink(startX, startY, -1, -1)
+	return visited == open && !bridge
+}
+
+// toMap fills the open cells with dots, leaves the starts empty and puts
+// energizers near the four corners.
+func (b *builder) toMap(player maps.StartPos, monsters []maps.StartPos) maps.Map {
+	w, h := b.cfg.Width, b.cfg.Height
+	m := maps.Map{
+		Width:         w,
+		Height:        h,
+		Name:          fmt.Sprintf("Generated %d", b.cfg.Seed),
+		Material:      b.cfg.Material,
+		MonsterCount:  len(monsters),
+		SpeedModifier: 1,
+		Steering:      maps.SteeringGreedy,
+		Wrap:          maps.WrapNone,
+		PlayerStart:   &player,
+		MonsterStarts: monsters,
+	}
+	m.Cells = make([][]maps.Cell, h)
+	for y := range m.Cells {
+		m.Cells[y] = make([]maps.Cell, w)
+		for x := range m.Cells[y] {
+			switch {
+			case b.wall[y][x]:
+				m.Cells[y][x] = maps.Wall
+			case b.reserved[[2]int{x, y}]:
+				m.Cells[y][x] = maps.Empty
+			default:
+				m.Cells[y][x] = maps.Dot
+			}
+		}
+	}
+
+	for _, corner := range [][2]int{{1, 1}, {1, h - 2}} {
+		x, y, ok := b.nearestDot(m.Cells, corner[0], corner[1])
+		if !ok {
+			continue
+		}
+		for _, cell := range [][2]int{{x, y}, {w - 1 - x, y}} {
+			if m.Cells[cell[1]][cell[0]] == maps.Dot {
+				m.Cells[cell[1]][cell[0]] = maps.Energizer
+			}
+		}
+	}
+	return m
+}
+
+// nearestDot finds the dot closest to (cx,cy) on the left half, scanning
+// rings of growing distance so ties resolve the same way every time.
+func (b *builder) nearestDot(cells [][]maps.Cell, cx, cy int) (int, int, bool) {
+	w, h := b.cfg.Width, b.cfg.Height
+	for dist := 0; dist < w+h; dist++ {
+		for y := 1; y < h-1; y++ {
+			for x := 1; x <= w-1-x; x++ {
+				if abs(x-cx)+abs(y-cy) == dist && cells[y][x] == maps.Dot {
+					return x, y, true
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
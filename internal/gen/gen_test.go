@@ -0,0 +1,124 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+func TestGenerateIsReproducible(t *testing.T) {
+	cfg := Config{Width: 24, Height: 16, Density: DefaultDensity, Monsters: 2, Seed: 7}
+	a, err := Generate(cfg)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	b, err := Generate(cfg)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("same seed gave different levels")
+	}
+
+	cfg.Seed++
+	c, err := Generate(cfg)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if reflect.DeepEqual(a.Cells, c.Cells) {
+		t.Errorf("different seeds gave the same grid")
+	}
+}
+
+func TestGenerateIsSymmetricWithoutPockets(t *testing.T) {
+	configs := []Config{
+		{Width: 24, Height: 16, Density: DefaultDensity, Monsters: 2},
+		{Width: 21, Height: 15, Density: maxDensity, Monsters: 4, MirrorVertical: true},
+		{Width: 5, Height: 5, Density: DefaultDensity, Monsters: 1},
+		{Width: 30, Height: 9, Density: 0, Monsters: 0},
+	}
+	for _, cfg := range configs {
+		for seed := int64(0); seed < 20; seed++ {
+			cfg.Seed = seed
+			m, err := Generate(cfg)
+			if err != nil {
+				t.Fatalf("Generate(%+v) error = %v", cfg, err)
+			}
+			if err := maps.Validate(&m); err != nil {
+				t.Errorf("Generate(%+v) is invalid: %v", cfg, err)
+			}
+			if m.Width != cfg.Width || m.Height != cfg.Height || len(m.MonsterStarts) != cfg.Monsters {
+				t.Errorf("Generate(%+v) gave %dx%d with %d monsters", cfg, m.Width, m.Height, len(m.MonsterStarts))
+			}
+
+			for y := 0; y < m.Height; y++ {
+				for x := 0; x < m.Width; x++ {
+					isWall := m.Cells[y][x] == maps.Wall
+					if isWall != (m.Cells[y][m.Width-1-x] == maps.Wall) {
+						t.Fatalf("seed %d: wall at (%d,%d) is not mirrored left to right", seed, x, y)
+					}
+					if cfg.MirrorVertical && isWall != (m.Cells[m.Height-1-y][x] == maps.Wall) {
+						t.Fatalf("seed %d: wall at (%d,%d) is not mirrored top to bottom", seed, x, y)
+					}
+					if isWall {
+						continue
+					}
+					exits := 0
+					for _, d := range [][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+						if m.Cells[y+d[1]][x+d[0]] != maps.Wall {
+							exits++
+						}
+					}
+					if exits < 2 {
+						t.Fatalf("seed %d: (%d,%d) is a dead end", seed, x, y)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestGenerateLevelsLoad(t *testing.T) {
+	levels, err := GenerateLevels(Config{Width: 24, Height: 16, Density: DefaultDensity, Monsters: 3, Seed: 1}, 3)
+	if err != nil {
+		t.Fatalf("GenerateLevels() error = %v", err)
+	}
+	if levels[0].Name == levels[1].Name {
+		t.Errorf("levels share the name %q", levels[0].Name)
+	}
+
+	path := filepath.Join(t.TempDir(), "generated.txt")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if err := maps.WriteMaps(file, levels); err != nil {
+		t.Fatalf("WriteMaps() error = %v", err)
+	}
+	file.Close()
+
+	loaded, err := maps.LoadMapsFromFile(path)
+	if err != nil {
+		t.Fatalf("generated levels do not load: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, levels) {
+		t.Errorf("loaded levels differ from the generated ones")
+	}
+}
+
+func TestGenerateRejectsBadConfig(t *testing.T) {
+	tests := []Config{
+		{Width: 4, Height: 16, Density: DefaultDensity},
+		{Width: 24, Height: 16, Density: -0.1},
+		{Width: 24, Height: 16, Density: 0.9},
+		{Width: 8, Height: 8, Density: DefaultDensity, Monsters: 7},
+	}
+	for _, cfg := range tests {
+		if _, err := Generate(cfg); err == nil {
+			t.Errorf("Generate(%+v) succeeded, want an error", cfg)
+		}
+	}
+}
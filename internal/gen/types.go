@@ -0,0 +1,19 @@
+package gen
+
+// Config describes the levels to generate. Level i of a batch uses Seed+i,
+// so the same config always gives the same levels.
+type Config struct {
+	Width, Height  int
+	Density        float64 // Share of the cells inside the border that become walls
+	Monsters       int
+	MirrorVertical bool // Mirror top to bottom as well as left to right
+	Seed           int64
+	Material       string
+}
+
+// builder holds the grid of one level while it is generated.
+type builder struct {
+	cfg      Config
+	wall     [][]bool
+	reserved map[[2]int]bool // Start cells that must stay open
+}
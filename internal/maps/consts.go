@@ -6,4 +6,98 @@ const (
 	Empty Cell = iota
 	Wall
 	Dot
+	Energizer // Power pellet; eating it frightens the monsters
 )
+
+// Monster steering modes selectable per level with the "steering" key.
+const (
+	SteeringGreedy       = "greedy"   // Original axis-distance heuristic
+	SteeringShortestPath = "shortest" // Turn toward the smallest BFS distance to the player
+)
+
+// Monster personalities for the "monsterBrains" key.
+const (
+	BrainClassic  = "classic"
+	BrainChaser   = "chaser"
+	BrainAmbusher = "ambusher"
+	BrainWanderer = "wanderer"
+	BrainPatrol   = "patrol"
+)
+
+// Edge wrapping modes for the "wrap" key.
+const (
+	WrapNone       = "none"
+	WrapHorizontal = "horizontal" // Left and right edges are joined
+	WrapVertical   = "vertical"   // Top and bottom edges are joined
+	WrapBoth       = "both"
+)
+
+// Diagnostic severities.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Map file formats. Format 2 is opt-in with a "format: 2" first line.
+const (
+	formatV1 = 1
+	formatV2 = 2
+)
+
+// headerKeys are the keys of a format 2 file header.
+var headerKeys = []string{"format", "pack", "author", "version", "legend"}
+
+// Glyphs a grid character can stand for.
+const (
+	GlyphEmpty Glyph = iota
+	GlyphWall
+	GlyphDot
+	GlyphEnergizer
+	GlyphPlayer  // Player start on an empty cell
+	GlyphPlayer2 // Second player start on an empty cell
+	GlyphMonster // Monster start on an empty cell
+)
+
+// glyphNames name the glyphs in legend lines, indexed by Glyph.
+var glyphNames = []string{"empty", "wall", "dot", "energizer", "player", "player2", "monster"}
+
+// defaultGlyphs are the built-in grid characters of format 2; the first
+// one listed for a glyph is the one written.
+var defaultGlyphs = Legend{
+	{'O', GlyphWall}, {'o', GlyphWall}, {'0', GlyphWall},
+	{'-', GlyphDot}, {'*', GlyphEnergizer},
+	{'P', GlyphPlayer}, {'p', GlyphPlayer2}, {'M', GlyphMonster},
+	{'.', GlyphEmpty},
+}
+
+// formatV1Glyphs are the built-in grid characters of format 1. A 'p' was
+// empty space in the original format and stays so; player2Start gives the
+// second player start there.
+var formatV1Glyphs = Legend{
+	{'O', GlyphWall}, {'o', GlyphWall}, {'0', GlyphWall},
+	{'-', GlyphDot}, {'*', GlyphEnergizer},
+	{'P', GlyphPlayer}, {'M', GlyphMonster},
+	{'.', GlyphEmpty},
+}
+
+// metaKeys are the metadata keys the loader knows, as documented; keys
+// match case-insensitively.
+var metaKeys = []string{
+	"name", "material", "author", "description", "difficulty",
+	"playerStart", "player2Start", "monsterStarts", "monsterBrains", "monsters",
+	"speedModifier", "steering", "wrap", "parTime",
+}
+
+// gridKeyRunes are the grid characters that can also make up a metadata
+// key, so a grid row with a stray ':' or '=' can be pointed out.
+const gridKeyRunes = "OoPpM0123456789"
+
+// maxKeyTypoDistance is how many edits away from a known key an unknown
+// one may be to count as a typo.
+const maxKeyTypoDistance = 2
+
+// maxTeleporterPairs is how many pairs the digits 1-9 can mark.
+const maxTeleporterPairs = 9
+
+// neighborSteps are the four grid moves in (dx, dy) form.
+var neighborSteps = [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
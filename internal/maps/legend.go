@@ -0,0 +1,116 @@
+package maps
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// parseLegend reads a legend line value such as "#=wall .=dot _=empty".
+// Each entry is a character, '=' and a glyph name; wall, dot and empty are
+// required so every level can be written back.
+func parseLegend(value string) (Legend, error) {
+	var legend Legend
+	seen := make(map[rune]bool)
+	for _, field := range strings.Fields(value) {
+		runes := []rune(field)
+		if len(runes) < 3 || runes[1] != '=' {
+			return nil, fmt.Errorf("invalid legend entry %q (want character=glyph)", field)
+		}
+		r, name := runes[0], strings.ToLower(string(runes[2:]))
+		switch {
+		case r == ':' || r == '=':
+			return nil, fmt.Errorf("legend character %q would make grid rows read as metadata", r)
+		case r >= '1' && r <= '9':
+			return nil, fmt.Errorf("legend character %q is a teleporter digit", r)
+		case unicode.IsSpace(r):
+			return nil, fmt.Errorf("legend characters must be visible")
+		case seen[r]:
+			return nil, fmt.Errorf("legend lists %q twice", r)
+		}
+		glyph := -1
+		for i, n := range glyphNames {
+			if n == name {
+				glyph = i
+			}
+		}
+		if glyph < 0 {
+			return nil, fmt.Errorf("unknown glyph %q in legend (must be one of %s)", name, strings.Join(glyphNames, ", "))
+		}
+		seen[r] = true
+		legend = append(legend, LegendEntry{Rune: r, Glyph: Glyph(glyph)})
+	}
+	for _, g := range []Glyph{GlyphWall, GlyphDot, GlyphEmpty} {
+		if _, ok := legend.runeFor(g); !ok {
+			return nil, fmt.Errorf("legend has no character for %s", glyphNames[g])
+		}
+	}
+	return legend, nil
+}
+
+// gridGlyphs returns the characters levels of format are drawn with:
+// legend, or the built-in ones when there is none.
+func gridGlyphs(format int, legend Legend) Legend {
+	switch {
+	case legend != nil:
+		return legend
+	case format == formatV2:
+		return defaultGlyphs
+	}
+	return formatV1Glyphs
+}
+
+// glyph looks up what r stands for.
+func (l Legend) glyph(r rune) (Glyph, bool) {
+	for _, e := range l {
+		if e.Rune == r {
+			return e.Glyph, true
+		}
+	}
+	return 0, false
+}
+
+// runeFor returns the first character the legend lists for g.
+func (l Legend) runeFor(g Glyph) (rune, bool) {
+	for _, e := range l {
+		if e.Glyph == g {
+			return e.Rune, true
+		}
+	}
+	return 0, false
+}
+
+// chars lists the legend's characters, for messages.
+func (l Legend) chars() string {
+	var b strings.Builder
+	for _, e := range l {
+		b.WriteRune(e.Rune)
+	}
+	return b.String()
+}
+
+// String writes the legend the way parseLegend reads it.
+func (l Legend) String() string {
+	parts := make([]string, len(l))
+	for i, e := range l {
+		parts[i] = fmt.Sprintf("%c=%s", e.Rune, glyphNames[e.Glyph])
+	}
+	return strings.Join(parts, " ")
+}
+
+// legendRunes peeks at a header line for the characters a legend maps, so
+// the scanner can tell grid rows from comments before the header is parsed.
+func legendRunes(line string) map[rune]bool {
+	key, value := parseMapMetaLine(line)
+	if !strings.EqualFold(key, "legend") {
+		return nil
+	}
+	runes := make(map[rune]bool)
+	for _, field := range strings.Fields(value) {
+		for _, r := range field {
+			runes[r] = true
+			break
+		}
+	}
+	return runes
+}
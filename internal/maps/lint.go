@@ -0,0 +1,142 @@
+package maps
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// Lint checks a map file like LoadMapsFromFile but keeps going, returning
+// every problem it finds with its position in the file, in file order. Dead ends that
+// only ValidateStrict refuses come back as warnings. The error is only for
+// a file that cannot be read.
+func Lint(filename string) ([]Diagnostic, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LintReader(filename, file)
+}
+
+// LintReader lints a map pack read from r; name is used as the file of the
+// diagnostics.
+func LintReader(name string, r io.Reader) ([]Diagnostic, error) {
+	pack, err := scanPack(r)
+	var srcErr *sourceError
+	if errors.As(err, &srcErr) {
+		return []Diagnostic{{File: name, Line: srcErr.line, Col: srcErr.col, Severity: SeverityError, Msg: srcErr.msg}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var diags []Diagnostic
+	var header PackHeader
+	if pack.header != nil {
+		header, _ = parseHeader(pack, func(err error) {
+			d := locate(*pack.header, sectionSource{}, err)
+			d.File, d.Severity = name, SeverityError
+			if errors.As(err, &srcErr) && srcErr.warning {
+				d.Severity = SeverityWarning
+			}
+			diags = append(diags, d)
+		})
+	}
+	if len(pack.sections) == 0 {
+		diags = append(diags, Diagnostic{File: name, Line: 1, Col: 1, Severity: SeverityError, Msg: "map file has no levels"})
+		return diags, nil
+	}
+
+	var first *Map // First level that parsed, the one others are measured against
+	firstLevel := 0
+	for i, sec := range pack.sections {
+		level := i + 1
+		var src sectionSource
+		add := func(severity Severity) func(error) bool {
+			return func(err error) bool {
+				d := locate(sec, src, err)
+				d.File, d.Level, d.Severity = name, level, severity
+				diags = append(diags, d)
+				return true
+			}
+		}
+
+		var parseErrs []error
+		m, parsed, err := parseSection(sec.lines, pack.format, header.Legend, func(err error) {
+			parseErrs = append(parseErrs, err)
+		})
+		src = parsed
+		for _, perr := range parseErrs {
+			var srcErr *sourceError
+			if errors.As(perr, &srcErr) && srcErr.warning {
+				add(SeverityWarning)(perr)
+			} else {
+				add(SeverityError)(perr)
+			}
+		}
+		if err != nil {
+			add(SeverityError)(err)
+			continue
+		}
+
+		if first == nil {
+			first, firstLevel = &m, level
+		} else if m.Width != first.Width || m.Height != first.Height {
+			add(SeverityError)(&sourceError{line: src.rows[0], col: src.indents[0] + 1, msg: fmt.Sprintf(
+				"level %d is %dx%d but level %d is %dx%d; all levels in a file must have the same dimensions",
+				level, m.Width, m.Height, firstLevel, first.Width, first.Height)})
+		}
+		checkMap(&m, add(SeverityError))
+		checkStrict(&m, add(SeverityWarning))
+	}
+	sort.SliceStable(diags, func(i, j int) bool {
+		if diags[i].Line != diags[j].Line {
+			return diags[i].Line < diags[j].Line
+		}
+		return diags[i].Col < diags[j].Col
+	})
+	return diags, nil
+}
+
+// locate finds the line and column of a problem reported for sec.
+func locate(sec numberedSection, src sectionSource, err error) Diagnostic {
+	d := Diagnostic{Line: 1, Col: 1, Msg: err.Error()}
+	if len(sec.lineNos) > 0 {
+		d.Line = sec.lineNos[0]
+	}
+	var cellErr *CellError
+	var srcErr *sourceError
+	var metaErr *metaError
+	switch {
+	case errors.As(err, &cellErr):
+		if cellErr.Y >= 0 && cellErr.Y < len(src.rows) {
+			d.Line = sec.lineNos[src.rows[cellErr.Y]]
+			d.Col = src.indents[cellErr.Y] + cellErr.X + 1
+		}
+	case errors.As(err, &srcErr):
+		d.Line, d.Col = sec.lineNos[srcErr.line], srcErr.col
+	case errors.As(err, &metaErr):
+		if line, ok := src.meta[metaErr.key]; ok {
+			d.Line = sec.lineNos[line]
+		}
+	}
+	return d
+}
+
+// HasErrors reports whether any of diags is an error rather than a warning.
+func HasErrors(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// String formats d the way compilers do, "file:line:col: severity: message".
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s", d.File, d.Line, d.Col, d.Severity, d.Msg)
+}
@@ -1,51 +1,34 @@
 package maps
 
 import (
-	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
 )
 
 func LoadMapsFromFile(filename string) ([]Map, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	var maps []Map
-	var currentLines []string
-	scanner := bufio.NewScanner(file)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		// Check for level separator
-		if line == "---" {
-			if len(currentLines) > 0 {
-				m, err := parseMap(currentLines)
-				if err != nil {
-					return nil, err
-				}
-				if err := validateMap(&m); err != nil {
-					return nil, err
-				}
-				maps = append(maps, m)
-				currentLines = nil
-			}
-			continue
-		}
+	_, levels, err := LoadPackFromFile(filename)
+	return levels, err
+}
 
-		if line != "" {
-			currentLines = append(currentLines, line)
-		}
-	}
+// ReadMaps parses and validates a map pack from r, like LoadMapsFromFile.
+func ReadMaps(r io.Reader) ([]Map, error) {
+	_, levels, err := ReadPack(r)
+	return levels, err
+}
 
-	// Don't forget the last map
-	if len(currentLines) > 0 {
-		m, err := parseMap(currentLines)
+// loadSections parses and validates the levels of a pack and checks that
+// they share one size.
+func loadSections(pack scannedPack, legend Legend) ([]Map, error) {
+	var maps []Map
+	for _, sec := range pack.sections {
+		m, _, err := parseSection(sec.lines, pack.format, legend, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -55,10 +38,6 @@ func LoadMapsFromFile(filename string) ([]Map, error) {
 		maps = append(maps, m)
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
-
 	// Validate that all maps have the same dimensions
 	if len(maps) > 1 {
 		firstWidth := maps[0].Width
@@ -74,156 +53,372 @@ func LoadMapsFromFile(filename string) ([]Map, error) {
 	return maps, nil
 }
 
-func parseMapMetaLine(line string) (key, value string) {
-	trimmed := strings.TrimSpace(line)
-	// Try "key: value" format
-	if idx := strings.Index(trimmed, ":"); idx != -1 {
-		key = strings.TrimSpace(trimmed[:idx])
-		value = strings.TrimSpace(trimmed[idx+1:])
-		return
+// ParseMapsFromFile reads every level of a map file without validating
+// them, for tools that point out problems instead of refusing the file.
+func ParseMapsFromFile(filename string) ([]Map, error) {
+	_, levels, err := ParsePackFromFile(filename)
+	return levels, err
+}
+
+// parseSections parses the levels of a pack without validating them.
+func parseSections(pack scannedPack, legend Legend) ([]Map, error) {
+	var maps []Map
+	for i, sec := range pack.sections {
+		m, _, err := parseSection(sec.lines, pack.format, legend, nil)
+		if err != nil {
+			return nil, fmt.Errorf("level %d: %v", i+1, err)
+		}
+		maps = append(maps, m)
 	}
-	// Try "key=value" format
-	if idx := strings.Index(trimmed, "="); idx != -1 {
-		key = strings.TrimSpace(trimmed[:idx])
-		value = strings.TrimSpace(trimmed[idx+1:])
-		return
+	return maps, nil
+}
+
+// HashFile returns the hex SHA-256 of a map file's contents. It identifies a
+// map pack independently of where the file lives.
+func HashFile(filename string) (string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
 	}
-	return "", ""
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
 }
 
 func parseMap(lines []string) (Map, error) {
+	m, _, err := parseSection(lines, formatV1, nil, nil)
+	return m, err
+}
+
+// parseSection parses the lines of one level in the given file format,
+// reading the grid with legend or, when it is nil, the built-in
+// characters. Without report it stops at the first problem; with it every
+// problem is handed over and parsing goes on, so only a section without
+// any grid still fails.
+func parseSection(lines []string, format int, legend Legend, report func(error)) (Map, sectionSource, error) {
+	src := sectionSource{meta: make(map[string]int)}
 	if len(lines) == 0 {
-		return Map{}, fmt.Errorf("empty map")
+		return Map{}, src, fmt.Errorf("empty map")
+	}
+	fail := func(err error) error {
+		if report == nil {
+			return err
+		}
+		report(err)
+		return nil
+	}
+	valueCol := func(line, value string) int {
+		if value == "" {
+			return len(line) + 1
+		}
+		return strings.Index(line, value) + 1
 	}
 
-	name := ""
-	material := ""
-	monsterCount := 1
-	monsterCountSet := false
-	speedModifier := 1.0
-	var playerStart *StartPos
-	var monsterStarts []StartPos
+	meta := levelMeta{
+		monsterCount:  1,
+		speedModifier: 1.0,
+		steering:      SteeringGreedy,
+		wrap:          WrapNone,
+	}
 	var gridPlayerStart *StartPos
+	var gridPlayer2Start *StartPos
 	var gridMonsterStarts []StartPos
 	var gridLines []string
 
-	for _, line := range lines {
+	for i, line := range lines {
 		trimmed := strings.TrimSpace(line)
 		if trimmed == "" {
 			continue
 		}
 
-		// Check if this looks like a metadata line
-		if strings.Contains(trimmed, ":") || strings.Contains(trimmed, "=") {
-			key, value := parseMapMetaLine(trimmed)
-			key = strings.ToLower(key)
-
-			switch key {
-			case "name":
-				name = value
-				continue
-			case "material":
-				material = value
-				continue
-			case "playerstart":
-				pos, err := parseStartPair(value)
-				if err != nil {
-					return Map{}, fmt.Errorf("invalid playerStart: %q", value)
+		if isMetaLine(trimmed) {
+			rawKey, value := parseMapMetaLine(trimmed)
+			indent := len(line) - len(strings.TrimLeftFunc(line, unicode.IsSpace))
+			if !validMetaKey(rawKey) {
+				if err := fail(&sourceError{line: i, col: indent + 1, msg: fmt.Sprintf("invalid metadata key %q; grid rows cannot contain ':' or '='", rawKey)}); err != nil {
+					return Map{}, src, err
 				}
-				playerStart = &pos
 				continue
-			case "monsterstart", "monsterstarts":
-				list, err := parseStartList(value)
-				if err != nil {
-					return Map{}, fmt.Errorf("invalid monsterStarts: %q", value)
-				}
-				monsterStarts = append(monsterStarts, list...)
-				continue
-			case "monsters":
-				count, err := strconv.Atoi(value)
-				if err != nil || count < 0 {
-					return Map{}, fmt.Errorf("invalid monsters count: %q", value)
-				}
-				monsterCount = count
-				monsterCountSet = true
-				continue
-			case "speedmodifier":
-				mod, err := strconv.ParseFloat(value, 64)
-				if err != nil || mod < 0.5 || mod > 2.0 {
-					return Map{}, fmt.Errorf("invalid speedModifier: %q (must be between 0.5 and 2.0)", value)
+			}
+			key := strings.ToLower(rawKey)
+			if key == "monsterstart" {
+				key = "monsterstarts"
+			}
+			known, err := meta.set(key, value)
+			if key == "legend" && format != formatV2 {
+				err = fmt.Errorf("legends need format 2; start the file with \"format: 2\" and put the legend in the header before the first ---")
+			}
+			if err != nil {
+				if err := fail(&sourceError{line: i, col: valueCol(line, value), msg: err.Error()}); err != nil {
+					return Map{}, src, err
 				}
-				speedModifier = mod
+			}
+			src.meta[key] = i
+			if known {
 				continue
 			}
+			if meta.extra == nil {
+				meta.extra = make(map[string]string)
+			}
+			meta.extra[rawKey] = value
+			if msg := unknownKeyWarning(rawKey); msg != "" && report != nil {
+				report(&sourceError{line: i, col: indent + 1, msg: msg, warning: true})
+			}
+			continue
 		}
 
-		// If we get here, treat it as grid data
+		// Format 2 keeps grid rows as written.
+		src.rows = append(src.rows, i)
+		if format == formatV2 {
+			gridLines = append(gridLines, line)
+			src.indents = append(src.indents, 0)
+			continue
+		}
 		gridLines = append(gridLines, trimmed)
+		src.indents = append(src.indents, len(line)-len(strings.TrimLeftFunc(line, unicode.IsSpace)))
 	}
 
 	if len(gridLines) == 0 {
-		return Map{}, fmt.Errorf("map has no grid data")
-	}
-	if !monsterCountSet {
-		monsterCount = 1
+		return Map{}, src, fmt.Errorf("map has no grid data")
 	}
 
-	height := len(gridLines)
+	// Format 1 places characters at their byte offset, so a multi-byte
+	// one leaves empty cells behind it; format 2 counts characters and
+	// wants every row as wide as the first.
+	rows := make([][]rune, len(gridLines))
 	width := 0
-	for _, line := range gridLines {
-		if len(line) > width {
-			width = len(line)
+	for y, line := range gridLines {
+		if format == formatV2 {
+			rows[y] = []rune(line)
+			if y == 0 {
+				width = len(rows[0])
+			} else if len(rows[y]) != width {
+				if err := fail(&sourceError{line: src.rows[y], col: min(len(rows[y]), width) + 1, msg: fmt.Sprintf(
+					"row %d is %d cells wide but the first row is %d; format 2 rows must all have the same width", y+1, len(rows[y]), width)}); err != nil {
+					return Map{}, src, err
+				}
+			}
+			continue
+		}
+		rows[y] = make([]rune, len(line))
+		for x, ch := range line {
+			rows[y][x] = ch
 		}
+		width = max(width, len(line))
 	}
 
+	height := len(gridLines)
 	cells := make([][]Cell, height)
 	for i := range cells {
 		cells[i] = make([]Cell, width)
 	}
+	teleporters := make(map[rune][]StartPos)
+	glyphs := gridGlyphs(format, legend)
 
-	for y, line := range gridLines {
-		for x, ch := range line {
-			switch ch {
-			case 'O', 'o', '0':
+	for y, row := range rows {
+		for x, ch := range row {
+			if x >= width {
+				break
+			}
+			// Format 1 keeps stray digits empty, as the original format did.
+			if ch >= '1' && ch <= '9' && format == formatV2 {
+				teleporters[ch] = append(teleporters[ch], StartPos{X: x, Y: y})
+				continue
+			}
+			glyph, ok := glyphs.glyph(ch)
+			if !ok && format == formatV2 {
+				msg := fmt.Sprintf("unknown grid character %q (the legend has %q)", ch, glyphs.chars())
+				if unicode.IsSpace(ch) {
+					empty, _ := glyphs.runeFor(GlyphEmpty)
+					msg = fmt.Sprintf("blank in grid row; format 2 marks empty cells with %q", empty)
+				}
+				if err := fail(cellErrorf(x, y, "%s", msg)); err != nil {
+					return Map{}, src, err
+				}
+			}
+			switch glyph {
+			case GlyphWall:
 				cells[y][x] = Wall
-			case '-':
+			case GlyphDot:
 				cells[y][x] = Dot
-			case 'P':
+			case GlyphEnergizer:
+				cells[y][x] = Energizer
+			case GlyphPlayer:
 				if gridPlayerStart != nil {
-					return Map{}, fmt.Errorf("multiple player starts found")
+					if err := fail(cellErrorf(x, y, "multiple player starts found; %q marks the player start", ch)); err != nil {
+						return Map{}, src, err
+					}
+					continue
 				}
 				pos := StartPos{X: x, Y: y}
 				gridPlayerStart = &pos
-				cells[y][x] = Empty
-			case 'M':
+			case GlyphPlayer2:
+				if gridPlayer2Start != nil {
+					if err := fail(cellErrorf(x, y, "multiple second player starts found; %q marks the second player start", ch)); err != nil {
+						return Map{}, src, err
+					}
+					continue
+				}
+				pos := StartPos{X: x, Y: y}
+				gridPlayer2Start = &pos
+			case GlyphMonster:
 				gridMonsterStarts = append(gridMonsterStarts, StartPos{X: x, Y: y})
-				cells[y][x] = Empty
-			default:
-				cells[y][x] = Empty
 			}
 		}
 	}
 
+	var teleports map[StartPos]StartPos
+	for ch := '1'; ch <= '9'; ch++ {
+		ends, found := teleporters[ch]
+		if !found {
+			continue
+		}
+		if len(ends) != 2 {
+			if err := fail(cellErrorf(ends[0].X, ends[0].Y, "teleporter %c needs exactly two cells, found %d", ch, len(ends))); err != nil {
+				return Map{}, src, err
+			}
+			continue
+		}
+		if teleports == nil {
+			teleports = make(map[StartPos]StartPos)
+		}
+		teleports[ends[0]] = ends[1]
+		teleports[ends[1]] = ends[0]
+	}
+
 	if gridPlayerStart != nil {
-		playerStart = gridPlayerStart
+		meta.playerStart = gridPlayerStart
+	}
+	if gridPlayer2Start != nil {
+		meta.player2Start = gridPlayer2Start
 	}
 	if len(gridMonsterStarts) > 0 {
-		monsterStarts = gridMonsterStarts
-		monsterCount = len(gridMonsterStarts)
-		monsterCountSet = true
+		meta.monsterStarts = gridMonsterStarts
+		meta.monsterCount = len(gridMonsterStarts)
+		meta.monsterCountSet = true
 	}
 
 	return Map{
 		Width:         width,
 		Height:        height,
 		Cells:         cells,
-		Name:          name,
-		Material:      material,
-		MonsterCount:  monsterCount,
-		SpeedModifier: speedModifier,
-		PlayerStart:   playerStart,
-		MonsterStarts: monsterStarts,
-	}, nil
+		Name:          meta.name,
+		Material:      meta.material,
+		Author:        meta.author,
+		Description:   meta.description,
+		Difficulty:    meta.difficulty,
+		Extra:         meta.extra,
+		MonsterCount:  meta.monsterCount,
+		SpeedModifier: meta.speedModifier,
+		Steering:      meta.steering,
+		PlayerStart:   meta.playerStart,
+		Player2Start:  meta.player2Start,
+		MonsterStarts: meta.monsterStarts,
+		MonsterBrains: meta.monsterBrains,
+		Wrap:          meta.wrap,
+		Teleports:     teleports,
+		ParTime:       meta.parTime,
+
+		defaultMonsters: !meta.monsterCountSet,
+	}, src, nil
+}
+
+// set applies one metadata key. It reports false for keys it does not know,
+// which end up in Map.Extra.
+func (lm *levelMeta) set(key, value string) (bool, error) {
+	switch key {
+	case "name":
+		lm.name = value
+	case "material":
+		lm.material = value
+	case "format":
+		return true, fmt.Errorf("format must be the first line of the file")
+	case "legend":
+		return true, fmt.Errorf("legend belongs in the file header of a format 2 pack")
+	case "author":
+		lm.author = value
+	case "description":
+		lm.description = value
+	case "difficulty":
+		lm.difficulty = value
+	case "playerstart":
+		pos, err := parseStartPair(value)
+		if err != nil {
+			return true, fmt.Errorf("invalid playerStart: %q", value)
+		}
+		lm.playerStart = &pos
+	case "player2start":
+		pos, err := parseStartPair(value)
+		if err != nil {
+			return true, fmt.Errorf("invalid player2Start: %q", value)
+		}
+		lm.player2Start = &pos
+	case "monsterstarts":
+		list, err := parseStartList(value)
+		if err != nil {
+			return true, fmt.Errorf("invalid monsterStarts: %q", value)
+		}
+		lm.monsterStarts = append(lm.monsterStarts, list...)
+	case "monsterbrains":
+		list, err := parseBrainList(value)
+		if err != nil {
+			return true, fmt.Errorf("invalid monsterBrains: %v", err)
+		}
+		lm.monsterBrains = list
+	case "monsters":
+		count, err := strconv.Atoi(value)
+		if err != nil || count < 0 {
+			return true, fmt.Errorf("invalid monsters count: %q", value)
+		}
+		lm.monsterCount = count
+		lm.monsterCountSet = true
+	case "speedmodifier":
+		mod, err := strconv.ParseFloat(value, 64)
+		if err != nil || mod < 0.5 || mod > 2.0 {
+			return true, fmt.Errorf("invalid speedModifier: %q (must be between 0.5 and 2.0)", value)
+		}
+		lm.speedModifier = mod
+	case "steering":
+		switch strings.ToLower(value) {
+		case SteeringGreedy, "classic":
+			lm.steering = SteeringGreedy
+		case SteeringShortestPath, "bfs":
+			lm.steering = SteeringShortestPath
+		default:
+			return true, fmt.Errorf("invalid steering: %q (must be %s or %s)", value, SteeringGreedy, SteeringShortestPath)
+		}
+	case "wrap":
+		switch strings.ToLower(value) {
+		case WrapNone, WrapHorizontal, WrapVertical, WrapBoth:
+			lm.wrap = strings.ToLower(value)
+		default:
+			return true, fmt.Errorf("invalid wrap: %q (must be %s, %s, %s or %s)", value, WrapNone, WrapHorizontal, WrapVertical, WrapBoth)
+		}
+	case "partime":
+		d, err := parseParTime(value)
+		if err != nil {
+			return true, fmt.Errorf("invalid parTime: %q (seconds or a duration like 1m30s)", value)
+		}
+		lm.parTime = d
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// parseParTime reads a positive number of seconds or a Go duration.
+func parseParTime(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		secs, ferr := strconv.ParseFloat(value, 64)
+		if ferr != nil {
+			return 0, err
+		}
+		d = time.Duration(secs * float64(time.Second))
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("parTime must be positive")
+	}
+	return d, nil
 }
 
 func parseStartPair(value string) (StartPos, error) {
@@ -265,84 +460,215 @@ func parseStartList(value string) ([]StartPos, error) {
 	return positions, nil
 }
 
+// parseBrainList reads "name; patrol x,y x,y; name" entries.
+func parseBrainList(value string) ([]BrainSpec, error) {
+	var specs []BrainSpec
+	for _, entry := range strings.Split(value, ";") {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+		spec := BrainSpec{Name: strings.ToLower(fields[0])}
+		switch spec.Name {
+		case BrainClassic, BrainChaser, BrainAmbusher, BrainWanderer:
+			if len(fields) > 1 {
+				return nil, fmt.Errorf("%s takes no route", spec.Name)
+			}
+		case BrainPatrol:
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("patrol needs at least one x,y waypoint")
+			}
+			for _, f := range fields[1:] {
+				pos, err := parseStartPair(f)
+				if err != nil {
+					return nil, fmt.Errorf("invalid patrol waypoint %q", f)
+				}
+				spec.Route = append(spec.Route, pos)
+			}
+		default:
+			return nil, fmt.Errorf("unknown brain %q", fields[0])
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// BrainFor returns the personality of the i-th monster. Monsters without an
+// explicit monsterBrains entry follow the level's steering mode.
+func (m *Map) BrainFor(i int) BrainSpec {
+	if i >= 0 && i < len(m.MonsterBrains) {
+		return m.MonsterBrains[i]
+	}
+	if m.Steering == SteeringShortestPath {
+		return BrainSpec{Name: BrainChaser}
+	}
+	return BrainSpec{Name: BrainClassic}
+}
+
+// Validate checks what LoadMapsFromFile checks for a single level: starts
+// and waypoints on open cells and every dot reachable. Problems at a known
+// cell are returned as a *CellError.
+func Validate(m *Map) error {
+	return validateMap(m)
+}
+
 func validateMap(m *Map) error {
+	var first error
+	checkMap(m, func(err error) bool {
+		first = err
+		return false
+	})
+	return first
+}
+
+// checkMap hands the problems of m to report in the order validateMap
+// finds them, until report returns false. Dots that cannot be reached are
+// reported once per group, with the rest of the group in CellError.Cells.
+func checkMap(m *Map, report func(error) bool) {
 	startX, startY := -1, -1
-	if m.PlayerStart != nil {
-		if m.PlayerStart.X < 0 || m.PlayerStart.Y < 0 || m.PlayerStart.X >= m.Width || m.PlayerStart.Y >= m.Height {
-			return fmt.Errorf("playerStart is out of bounds (%d,%d)", m.PlayerStart.X, m.PlayerStart.Y)
-		}
-		if m.Cells[m.PlayerStart.Y][m.PlayerStart.X] == Wall {
-			return fmt.Errorf("playerStart is on a wall (%d,%d)", m.PlayerStart.X, m.PlayerStart.Y)
-		}
-		startX, startY = m.PlayerStart.X, m.PlayerStart.Y
-	} else {
-		for y := 0; y < m.Height && startX == -1; y++ {
-			for x := 0; x < m.Width; x++ {
-				if m.Cells[y][x] != Wall {
-					startX, startY = x, y
-					break
-				}
+	if pos := m.PlayerStart; pos != nil {
+		if pos.X < 0 || pos.Y < 0 || pos.X >= m.Width || pos.Y >= m.Height {
+			if !report(&metaError{key: "playerstart", msg: fmt.Sprintf("playerStart is out of bounds (%d,%d)", pos.X, pos.Y)}) {
+				return
 			}
+		} else if m.Cells[pos.Y][pos.X] == Wall {
+			if !report(cellErrorf(pos.X, pos.Y, "playerStart is on a wall (%d,%d)", pos.X, pos.Y)) {
+				return
+			}
+		} else {
+			startX, startY = pos.X, pos.Y
 		}
-		if startX == -1 {
-			return fmt.Errorf("map has no walkable cells")
+	}
+	for y := 0; y < m.Height && startX == -1; y++ {
+		for x := 0; x < m.Width; x++ {
+			if m.Cells[y][x] != Wall {
+				startX, startY = x, y
+				break
+			}
 		}
 	}
+	if startX == -1 {
+		report(fmt.Errorf("map has no walkable cells"))
+		return
+	}
 
 	used := make(map[string]bool)
 	if m.PlayerStart != nil {
 		key := fmt.Sprintf("%d,%d", m.PlayerStart.X, m.PlayerStart.Y)
 		used[key] = true
 	}
-	for _, pos := range m.MonsterStarts {
+	checkStart := func(pos StartPos, name, key string) bool {
 		if pos.X < 0 || pos.Y < 0 || pos.X >= m.Width || pos.Y >= m.Height {
-			return fmt.Errorf("monsterStart is out of bounds (%d,%d)", pos.X, pos.Y)
+			return report(&metaError{key: key, msg: fmt.Sprintf("%s is out of bounds (%d,%d)", name, pos.X, pos.Y)})
 		}
 		if m.Cells[pos.Y][pos.X] == Wall {
-			return fmt.Errorf("monsterStart is on a wall (%d,%d)", pos.X, pos.Y)
+			return report(cellErrorf(pos.X, pos.Y, "%s is on a wall (%d,%d)", name, pos.X, pos.Y))
 		}
-		key := fmt.Sprintf("%d,%d", pos.X, pos.Y)
+		key = fmt.Sprintf("%d,%d", pos.X, pos.Y)
 		if used[key] {
-			return fmt.Errorf("duplicate start position (%d,%d)", pos.X, pos.Y)
+			return report(cellErrorf(pos.X, pos.Y, "duplicate start position (%d,%d)", pos.X, pos.Y))
 		}
 		used[key] = true
+		return true
+	}
+	if pos := m.Player2Start; pos != nil {
+		if !checkStart(*pos, "player2Start", "player2start") {
+			return
+		}
+	}
+	for _, pos := range m.MonsterStarts {
+		if !checkStart(pos, "monsterStart", "monsterstarts") {
+			return
+		}
 	}
 
-	dotCount := 0
-	dotStartX, dotStartY := -1, -1
-	for y := 0; y < m.Height; y++ {
-		for x := 0; x < m.Width; x++ {
-			if m.Cells[y][x] == Dot {
-				dotCount++
-				if dotStartX == -1 {
-					dotStartX, dotStartY = x, y
+	for _, spec := range m.MonsterBrains {
+		for _, wp := range spec.Route {
+			if wp.X < 0 || wp.Y < 0 || wp.X >= m.Width || wp.Y >= m.Height {
+				if !report(&metaError{key: "monsterbrains", msg: fmt.Sprintf("patrol waypoint is out of bounds (%d,%d)", wp.X, wp.Y)}) {
+					return
+				}
+			} else if m.Cells[wp.Y][wp.X] == Wall {
+				if !report(cellErrorf(wp.X, wp.Y, "patrol waypoint is on a wall (%d,%d)", wp.X, wp.Y)) {
+					return
 				}
 			}
 		}
 	}
-	if dotCount == 0 {
-		return nil
-	}
 
-	reachable := bfsReachable(m, startX, startY)
-	for y := 0; y < m.Height; y++ {
+	dotStartX, dotStartY := -1, -1
+	for y := 0; y < m.Height && dotStartX == -1; y++ {
 		for x := 0; x < m.Width; x++ {
-			if m.Cells[y][x] == Dot && !reachable[y][x] {
-				return fmt.Errorf("map '%s': unreachable dot at row %d, col %d", m.Name, y, x)
+			if m.HasDot(x, y) {
+				dotStartX, dotStartY = x, y
+				break
 			}
 		}
 	}
+	if dotStartX != -1 {
+		found, ok := reportDotGroups(m, bfsReachable(m, startX, startY), report, func(x, y int) string {
+			return fmt.Sprintf("map '%s': unreachable dot at row %d, col %d", m.Name, y, x)
+		})
+		if !ok {
+			return
+		}
+		if !found {
+			if _, ok := reportDotGroups(m, bfsReachable(m, dotStartX, dotStartY), report, func(x, y int) string {
+				return fmt.Sprintf("map '%s': separated dots; dot at row %d, col %d is disconnected", m.Name, y, x)
+			}); !ok {
+				return
+			}
+		}
+	}
+
+	checkEscapes(m, report)
+}
 
-	dotReachable := bfsReachable(m, dotStartX, dotStartY)
+// reportDotGroups reports each group of connected dots outside reachable,
+// at its first dot in reading order. It returns whether any was found and
+// whether report wants more.
+func reportDotGroups(m *Map, reachable [][]bool, report func(error) bool, msg func(x, y int) string) (found, ok bool) {
 	for y := 0; y < m.Height; y++ {
 		for x := 0; x < m.Width; x++ {
-			if m.Cells[y][x] == Dot && !dotReachable[y][x] {
-				return fmt.Errorf("map '%s': separated dots; dot at row %d, col %d is disconnected", m.Name, y, x)
+			if !m.HasDot(x, y) || reachable[y][x] {
+				continue
+			}
+			found = true
+			err := cellErrorf(x, y, "%s", msg(x, y)).(*CellError)
+			group := bfsReachable(m, x, y)
+			for gy := range group {
+				for gx := range group[gy] {
+					if !group[gy][gx] || reachable[gy][gx] {
+						continue
+					}
+					reachable[gy][gx] = true
+					if m.HasDot(gx, gy) && (gx != x || gy != y) {
+						err.Cells = append(err.Cells, StartPos{X: gx, Y: gy})
+					}
+				}
+			}
+			if !report(err) {
+				return found, false
 			}
 		}
 	}
+	return found, true
+}
+
+func cellErrorf(x, y int, format string, args ...any) error {
+	return &CellError{X: x, Y: y, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (e *CellError) Error() string {
+	return e.Msg
+}
 
-	return nil
+func (e *sourceError) Error() string {
+	return e.msg
+}
+
+func (e *metaError) Error() string {
+	return e.msg
 }
 
 func bfsReachable(m *Map, startX, startY int) [][]bool {
@@ -361,13 +687,9 @@ func bfsReachable(m *Map, startX, startY int) [][]bool {
 		queueX = queueX[1:]
 		queueY = queueY[1:]
 
-		neighbors := [][2]int{{x + 1, y}, {x - 1, y}, {x, y + 1}, {x, y - 1}}
-		for _, n := range neighbors {
-			nx, ny := n[0], n[1]
-			if nx < 0 || ny < 0 || nx >= m.Width || ny >= m.Height {
-				continue
-			}
-			if m.Cells[ny][nx] == Wall || reachable[ny][nx] {
+		for _, d := range neighborSteps {
+			nx, ny, ok := m.Neighbor(x, y, d[0], d[1])
+			if !ok || reachable[ny][nx] {
 				continue
 			}
 			reachable[ny][nx] = true
@@ -379,6 +701,113 @@ func bfsReachable(m *Map, startX, startY int) [][]bool {
 	return reachable
 }
 
+// Clone returns a deep copy of the map so a game can eat dots without
+// touching the loaded original.
+func (m *Map) Clone() Map {
+	c := *m
+	c.Cells = make([][]Cell, len(m.Cells))
+	for y := range m.Cells {
+		c.Cells[y] = append([]Cell(nil), m.Cells[y]...)
+	}
+	if m.PlayerStart != nil {
+		pos := *m.PlayerStart
+		c.PlayerStart = &pos
+	}
+	if m.Player2Start != nil {
+		pos := *m.Player2Start
+		c.Player2Start = &pos
+	}
+	c.MonsterStarts = append([]StartPos(nil), m.MonsterStarts...)
+	if m.Teleports != nil {
+		c.Teleports = make(map[StartPos]StartPos, len(m.Teleports))
+		for from, to := range m.Teleports {
+			c.Teleports[from] = to
+		}
+	}
+	if m.Extra != nil {
+		c.Extra = make(map[string]string, len(m.Extra))
+		for key, value := range m.Extra {
+			c.Extra[key] = value
+		}
+	}
+	c.MonsterBrains = make([]BrainSpec, len(m.MonsterBrains))
+	for i, spec := range m.MonsterBrains {
+		c.MonsterBrains[i] = BrainSpec{Name: spec.Name, Route: append([]StartPos(nil), spec.Route...)}
+	}
+	return c
+}
+
+// DistancesFrom returns the BFS step count from (startX, startY) to every
+// cell; walls and unreachable cells are -1.
+func (m *Map) DistancesFrom(startX, startY int) [][]int {
+	dist := make([][]int, m.Height)
+	for y := 0; y < m.Height; y++ {
+		dist[y] = make([]int, m.Width)
+		for x := 0; x < m.Width; x++ {
+			dist[y][x] = -1
+		}
+	}
+
+	if startX < 0 || startY < 0 || startX >= m.Width || startY >= m.Height {
+		return dist
+	}
+	if m.IsWall(startX, startY) {
+		return dist
+	}
+
+	queueX := []int{startX}
+	queueY := []int{startY}
+	dist[startY][startX] = 0
+
+	for len(queueX) > 0 {
+		x := queueX[0]
+		y := queueY[0]
+		queueX = queueX[1:]
+		queueY = queueY[1:]
+
+		for _, d := range neighborSteps {
+			nx, ny, ok := m.Neighbor(x, y, d[0], d[1])
+			if !ok || dist[ny][nx] != -1 {
+				continue
+			}
+			dist[ny][nx] = dist[y][x] + 1
+			queueX = append(queueX, nx)
+			queueY = append(queueY, ny)
+		}
+	}
+
+	return dist
+}
+
+// Neighbor returns the cell reached by stepping (dx, dy) from (x, y). Steps
+// off the grid come back on the other side when the level wraps that way,
+// and stepping onto a teleporter lands on its partner. ok is false when the
+// step runs into a wall or off a non-wrapping edge.
+func (m *Map) Neighbor(x, y, dx, dy int) (nx, ny int, ok bool) {
+	nx, ny = x+dx, y+dy
+	if m.WrapsHorizontally() && m.Width > 0 {
+		nx = (nx%m.Width + m.Width) % m.Width
+	}
+	if m.WrapsVertically() && m.Height > 0 {
+		ny = (ny%m.Height + m.Height) % m.Height
+	}
+	if m.IsWall(nx, ny) {
+		return x, y, false
+	}
+	if to, found := m.Teleports[StartPos{X: nx, Y: ny}]; found {
+		return to.X, to.Y, true
+	}
+	return nx, ny, true
+}
+
+func (m *Map) WrapsHorizontally() bool {
+	return m.Wrap == WrapHorizontal || m.Wrap == WrapBoth
+}
+
+func (m *Map) WrapsVertically() bool {
+	return m.Wrap == WrapVertical || m.Wrap == WrapBoth
+}
+
 func (m *Map) IsWall(x, y int) bool {
 	if x < 0 || y < 0 || x >= m.Width || y >= m.Height {
 		return true
@@ -386,11 +815,20 @@ func (m *Map) IsWall(x, y int) bool {
 	return m.Cells[y][x] == Wall
 }
 
+// HasDot reports whether there is anything to eat at (x, y), a plain dot
+// or an energizer.
 func (m *Map) HasDot(x, y int) bool {
 	if x < 0 || y < 0 || x >= m.Width || y >= m.Height {
 		return false
 	}
-	return m.Cells[y][x] == Dot
+	return m.Cells[y][x] == Dot || m.Cells[y][x] == Energizer
+}
+
+func (m *Map) IsEnergizer(x, y int) bool {
+	if x < 0 || y < 0 || x >= m.Width || y >= m.Height {
+		return false
+	}
+	return m.Cells[y][x] == Energizer
 }
 
 func (m *Map) EatDot(x, y int) {
@@ -399,11 +837,12 @@ func (m *Map) EatDot(x, y int) {
 	}
 }
 
+// CountDots counts what is left to eat, energizers included.
 func (m *Map) CountDots() int {
 	count := 0
 	for y := 0; y < m.Height; y++ {
 		for x := 0; x < m.Width; x++ {
-			if m.Cells[y][x] == Dot {
+			if m.HasDot(x, y) {
 				count++
 			}
 		}
@@ -411,27 +850,49 @@ func (m *Map) CountDots() int {
 	return count
 }
 
-func (m *Map) Render(playerX, playerY int, creatures interface{}) {
-	fmt.Print("\033[H\033[2J") // Clear screen
+// Render draws the grid with ANSI colours. Lines end in "\r\n" so the
+// output also lines up on a terminal in raw mode.
+func (m *Map) Render(w io.Writer, playerX, playerY int, creatures []Creature) {
+	occupied := make(map[StartPos]string, len(creatures))
+	for _, c := range creatures {
+		style := "\033[31mM\033[0m" // Red M (Monster)
+		if f, ok := c.(frightenable); ok && f.IsFrightened() {
+			style = "\033[34mM\033[0m" // Blue M (frightened)
+		}
+		occupied[StartPos{X: c.GetX(), Y: c.GetY()}] = style
+	}
 
+	var b strings.Builder
 	for y := 0; y < m.Height; y++ {
 		for x := 0; x < m.Width; x++ {
 			// Check if player is at this position
 			if x == playerX && y == playerY {
-				fmt.Print("\033[33mC\033[0m") // Yellow C (Pac-Man)
+				b.WriteString("\033[33mC\033[0m") // Yellow C (Pac-Man)
+				continue
+			}
+			if style, ok := occupied[StartPos{X: x, Y: y}]; ok {
+				b.WriteString(style)
+				continue
+			}
+
+			if _, ok := m.Teleports[StartPos{X: x, Y: y}]; ok {
+				b.WriteString("\033[35m@\033[0m") // Magenta @ (teleporter)
 				continue
 			}
 
 			// Render the cell
 			switch m.Cells[y][x] {
 			case Wall:
-				fmt.Print("\033[34mO\033[0m") // Blue O (Wall)
+				b.WriteString("\033[34mO\033[0m") // Blue O (Wall)
 			case Dot:
-				fmt.Print("\033[37m·\033[0m") // White dot
+				b.WriteString("\033[37m·\033[0m") // White dot
+			case Energizer:
+				b.WriteString("\033[1;37m●\033[0m") // Bright energizer
 			case Empty:
-				fmt.Print(" ")
+				b.WriteString(" ")
 			}
 		}
-		fmt.Println()
+		b.WriteString("\r\n")
 	}
+	io.WriteString(w, b.String())
 }
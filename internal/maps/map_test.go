@@ -1,8 +1,14 @@
 package maps
 
 import (
+	"errors"
+	"io"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoadMapsFromFile(t *testing.T) {
@@ -86,7 +92,8 @@ OOOO
 }
 
 func TestMapWallDetection(t *testing.T) {
-	content := `OOO
+	content := `monsters: 0
+OOO
 O-O
 OOO
 `
@@ -112,7 +119,8 @@ OOO
 }
 
 func TestMapDotCounting(t *testing.T) {
-	content := `OOO
+	content := `monsters: 0
+OOO
 O-O
 OOO
 `
@@ -144,6 +152,102 @@ OOO
 	}
 }
 
+func TestParseMapEnergizer(t *testing.T) {
+	m, err := parseMap([]string{
+		"OOOOO",
+		"O-*-O",
+		"OOOOO",
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse map: %v", err)
+	}
+
+	if !m.IsEnergizer(2, 1) || !m.HasDot(2, 1) {
+		t.Errorf("expected an energizer at (2,1), got cell %v", m.Cells[1][2])
+	}
+	if m.IsEnergizer(1, 1) {
+		t.Errorf("plain dot reported as energizer")
+	}
+	if count := m.CountDots(); count != 3 {
+		t.Errorf("CountDots = %d, want 3 including the energizer", count)
+	}
+	m.EatDot(2, 1)
+	if m.HasDot(2, 1) {
+		t.Errorf("energizer still there after eating it")
+	}
+}
+
+func TestParseMapWrapAndTeleporters(t *testing.T) {
+	m, _, err := parseSection([]string{
+		"wrap: horizontal",
+		"OOOOOOO",
+		"---O1--",
+		"OOOOOOO",
+		"O1---OO",
+		"OOOOOOO",
+	}, formatV2, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to parse map: %v", err)
+	}
+	if !m.WrapsHorizontally() || m.WrapsVertically() {
+		t.Fatalf("wrap = %q, want horizontal only", m.Wrap)
+	}
+
+	if x, y, ok := m.Neighbor(0, 1, -1, 0); !ok || x != 6 || y != 1 {
+		t.Errorf("stepping left off the edge = (%d,%d,%v), want (6,1,true)", x, y, ok)
+	}
+	if _, _, ok := m.Neighbor(1, 0, 0, -1); ok {
+		t.Errorf("stepping up through a wall should be blocked")
+	}
+	if x, y, ok := m.Neighbor(5, 1, -1, 0); !ok || x != 1 || y != 3 {
+		t.Errorf("stepping onto teleporter = (%d,%d,%v), want its partner (1,3,true)", x, y, ok)
+	}
+
+	dist := m.DistancesFrom(0, 1)
+	if dist[1][6] != 1 {
+		t.Errorf("distance through the tunnel = %d, want 1", dist[1][6])
+	}
+	if dist[3][4] != 6 {
+		t.Errorf("distance through the teleporter = %d, want 6", dist[3][4])
+	}
+
+	c := m.Clone()
+	c.Teleports[StartPos{X: 4, Y: 1}] = StartPos{}
+	if m.Teleports[StartPos{X: 4, Y: 1}] != (StartPos{X: 1, Y: 3}) {
+		t.Errorf("Clone shares teleporters with the original")
+	}
+}
+
+func TestParseMapRejectsBadWrapAndTeleporters(t *testing.T) {
+	if _, err := parseMap([]string{"wrap: sideways", "OOO", "O-O", "OOO"}); err == nil {
+		t.Errorf("expected error for unknown wrap mode")
+	}
+	if _, _, err := parseSection([]string{"OOOOO", "O1-1O", "O-1-O", "OOOOO"}, formatV2, nil, nil); err == nil {
+		t.Errorf("expected error for a teleporter with three cells")
+	}
+}
+
+func TestParseMapFormat1DigitsAreEmpty(t *testing.T) {
+	m, err := parseMap([]string{"OOOOO", "OP-1O", "OOOOO"})
+	if err != nil {
+		t.Fatalf("Failed to parse map: %v", err)
+	}
+	if m.Cells[1][3] != Empty || len(m.Teleports) != 0 {
+		t.Errorf("digit cell = %v with %d teleporter cells, want an empty cell as in the original format", m.Cells[1][3], len(m.Teleports))
+	}
+	if err := WriteMaps(io.Discard, []Map{m}); err != nil {
+		t.Errorf("WriteMaps() error = %v", err)
+	}
+
+	m, _, err = parseSection([]string{"OOOOOO", "O1P-1O", "OOOOOO"}, formatV2, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to parse format 2 level: %v", err)
+	}
+	if err := WriteMaps(io.Discard, []Map{m}); err == nil || !strings.Contains(err.Error(), "format 2") {
+		t.Errorf("WriteMaps() = %v, want teleporters refused in format 1", err)
+	}
+}
+
 func TestParseMapGridStarts(t *testing.T) {
 	mapLines := []string{
 		"monsters: 5",
@@ -170,41 +274,46 @@ func TestParseMapGridStarts(t *testing.T) {
 	if m.MonsterCount != 2 {
 		t.Errorf("MonsterCount = %d, want 2", m.MonsterCount)
 	}
+	if m.Player2Start != nil {
+		t.Errorf("Player2Start = %+v, want none", m.Player2Start)
+	}
 }
 
-func TestPlayerMovement(t *testing.T) {
-	content := `OOO
-O-O
-OOO
-`
-	tmpFile, err := os.CreateTemp("", "test_map_*.txt")
+func TestParseMapSecondPlayerStart(t *testing.T) {
+	m, _, err := parseSection([]string{
+		"OOOOO",
+		"OP-pO",
+		"OOOOO",
+	}, formatV2, nil, nil)
 	if err != nil {
-		t.Fatalf("Failed to create temp file: %v", err)
+		t.Fatalf("Failed to parse map: %v", err)
+	}
+	if m.Player2Start == nil || *m.Player2Start != (StartPos{X: 3, Y: 1}) {
+		t.Errorf("Player2Start = %+v, want (3,1)", m.Player2Start)
 	}
-	defer os.Remove(tmpFile.Name())
-
-	tmpFile.WriteString(content)
-	tmpFile.Close()
-
-	maps, _ := LoadMapsFromFile(tmpFile.Name())
-	m := &maps[0]
 
-	player := NewPlayer(1, 1)
+	if _, _, err := parseSection([]string{"OOOOO", "OpPpO", "OOOOO"}, formatV2, nil, nil); err == nil {
+		t.Errorf("expected error for two second player starts")
+	}
 
-	// Try to move right (should hit wall)
-	player.SetDirection(Right)
-	player.Move(m)
-	if player.X != 1 || player.Y != 1 {
-		t.Errorf("Player should not move through wall, got position (%d, %d)", player.X, player.Y)
+	// Format 1 keeps 'p' as empty space, as the original format did.
+	m, err = parseMap([]string{"OOOOO", "OP-pO", "OOOOO"})
+	if err != nil {
+		t.Fatalf("Failed to parse map: %v", err)
+	}
+	if m.Player2Start != nil || m.Cells[1][3] != Empty {
+		t.Errorf("format 1 'p' gave Player2Start %+v and cell %v, want an empty cell", m.Player2Start, m.Cells[1][3])
 	}
 
-	// Try to move left (should hit wall)
-	player.SetDirection(Left)
-	player.Move(m)
-	if player.X != 1 || player.Y != 1 {
-		t.Errorf("Player should not move through wall, got position (%d, %d)", player.X, player.Y)
+	m, err = parseMap([]string{"player2Start: 1,1", "OOOOO", "OP--O", "OOOOO"})
+	if err != nil {
+		t.Fatalf("Failed to parse map: %v", err)
+	}
+	if err := validateMap(&m); err == nil {
+		t.Errorf("expected error for both players starting on one cell")
 	}
 }
+
 func TestParseMapMetaLine(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -255,6 +364,44 @@ func TestParseMapMetaLine(t *testing.T) {
 	}
 }
 
+func TestParseMapKeepsUnknownKeys(t *testing.T) {
+	m, err := parseMap([]string{
+		"author: Jana",
+		"difficulty = hard",
+		"speedModifer: 1.5",
+		"Theme: night",
+		"OOOOO",
+		"OP--O",
+		"OOOOO",
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse map: %v", err)
+	}
+	if m.Width != 5 || m.Height != 3 {
+		t.Errorf("map is %dx%d, want 5x3; metadata must not become grid rows", m.Width, m.Height)
+	}
+	if m.Author != "Jana" || m.Difficulty != "hard" || m.SpeedModifier != 1 {
+		t.Errorf("author %q, difficulty %q, speed %v; want Jana, hard and the default speed", m.Author, m.Difficulty, m.SpeedModifier)
+	}
+	want := map[string]string{"speedModifer": "1.5", "Theme": "night"}
+	if !reflect.DeepEqual(m.Extra, want) {
+		t.Errorf("Extra = %v, want %v", m.Extra, want)
+	}
+
+	if _, err := parseMap([]string{"OOOOO", "O-O:O", "OP--O", "OOOOO"}); err == nil {
+		t.Error("expected an error for a grid row holding ':'")
+	}
+
+	diags, err := LintReader("typo.txt", strings.NewReader("speedModifer: 1.5\ncolour: red\nmonsters: 0\nOOOOO\nO*P*O\nOOOOO\n"))
+	if err != nil {
+		t.Fatalf("LintReader() error = %v", err)
+	}
+	wantDiag := `typo.txt:1:1: warning: unknown key "speedModifer" kept as a custom property; did you mean "speedModifier"?`
+	if len(diags) != 1 || diags[0].String() != wantDiag {
+		t.Errorf("LintReader() = %v, want only %s", diags, wantDiag)
+	}
+}
+
 func TestParseMapSpeedModifier(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -355,6 +502,33 @@ func TestParseMapSpeedModifier(t *testing.T) {
 	}
 }
 
+func TestParseMapParTime(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "45", want: 45 * time.Second},
+		{value: "2.5", want: 2500 * time.Millisecond},
+		{value: "1m30s", want: 90 * time.Second},
+		{value: "0", wantErr: true},
+		{value: "-10s", wantErr: true},
+		{value: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			m, err := parseMap([]string{"parTime: " + tt.value, "OOOOO", "O---O", "OOOOO"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseMap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && m.ParTime != tt.want {
+				t.Errorf("ParTime = %v, want %v", m.ParTime, tt.want)
+			}
+		})
+	}
+}
+
 func TestMapRequiresTwoEscapesWhenMonstersPresent(t *testing.T) {
 	content := `monsters: 1
 OOO
@@ -400,3 +574,552 @@ OOO
 		t.Fatalf("Expected map to load without monsters, got error: %v", err)
 	}
 }
+
+func TestMapChecksEscapesWithDefaultMonsters(t *testing.T) {
+	// No monsters line: the level still spawns the default monster.
+	m, err := parseMap([]string{"OOO", "O-O", "OOO"})
+	if err != nil {
+		t.Fatalf("Failed to parse map: %v", err)
+	}
+	if m.MonsterCount != 1 {
+		t.Fatalf("MonsterCount = %d, want the default 1", m.MonsterCount)
+	}
+	if err := Validate(&m); err == nil || !strings.Contains(err.Error(), "no escape route") {
+		t.Errorf("Validate() = %v, want the missing escape route reported", err)
+	}
+
+	m, err = parseMap([]string{
+		"playerStart: 5,3",
+		"OOOOOOO",
+		"O-----O",
+		"O-O-O-O",
+		"O---O-O",
+		"OOOOOOO",
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse map: %v", err)
+	}
+	if err := Validate(&m); err == nil || !strings.Contains(err.Error(), "5,3 5,2 5,1 4,1") {
+		t.Errorf("Validate() = %v, want the player start trap reported", err)
+	}
+}
+
+func TestParseMapSteering(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "", want: SteeringGreedy},
+		{value: "greedy", want: SteeringGreedy},
+		{value: "shortest", want: SteeringShortestPath},
+		{value: "BFS", want: SteeringShortestPath},
+		{value: "smart", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		lines := []string{"OOOOO", "O---O", "OOOOO"}
+		if tt.value != "" {
+			lines = append([]string{"steering: " + tt.value}, lines...)
+		}
+		m, err := parseMap(lines)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("steering %q: error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && m.Steering != tt.want {
+			t.Errorf("steering %q: got %q, want %q", tt.value, m.Steering, tt.want)
+		}
+	}
+}
+
+func TestParseMapMonsterBrains(t *testing.T) {
+	m, err := parseMap([]string{
+		"steering: shortest",
+		"monsterBrains: ambusher; patrol 1,1 3,1; wanderer",
+		"OOOOO",
+		"OM-MO",
+		"O---O",
+		"OM-MO",
+		"OOOOO",
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse map: %v", err)
+	}
+
+	want := []string{BrainAmbusher, BrainPatrol, BrainWanderer, BrainChaser}
+	for i, name := range want {
+		if got := m.BrainFor(i).Name; got != name {
+			t.Errorf("BrainFor(%d) = %q, want %q", i, got, name)
+		}
+	}
+	if route := m.BrainFor(1).Route; len(route) != 2 || route[1] != (StartPos{X: 3, Y: 1}) {
+		t.Errorf("patrol route = %+v", route)
+	}
+
+	if _, err := parseMap([]string{"monsterBrains: sleepy", "OOO", "O-O", "OOO"}); err == nil {
+		t.Error("expected an error for an unknown brain")
+	}
+}
+
+func TestWriteMapsRoundTrip(t *testing.T) {
+	levels, err := LoadMapsFromFile("../../maps/maps.txt")
+	if err != nil {
+		t.Fatalf("Failed to load maps: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "written.txt")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if err := WriteMaps(file, levels); err != nil {
+		t.Fatalf("WriteMaps() error = %v", err)
+	}
+	file.Close()
+
+	reloaded, err := LoadMapsFromFile(path)
+	if err != nil {
+		t.Fatalf("Failed to reload written maps: %v", err)
+	}
+	if !reflect.DeepEqual(reloaded, levels) {
+		t.Errorf("written maps do not parse back to the originals")
+	}
+}
+
+func TestWriteMapsRoundTripCases(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+	}{
+		{"grid letters", []string{
+			"OOOOOO",
+			"OP M O",
+			"O-*-MO",
+			"OOOOOO",
+		}},
+		{"starts on dots", []string{
+			"name: Dotted Starts",
+			"playerStart: 1,1",
+			"monsterStarts: 4,2; 2,1",
+			"OOOOOO",
+			"O----O",
+			"O----O",
+			"OOOOOO",
+		}},
+		{"starts out of row order", []string{
+			"monsterBrains: ambusher; patrol 1,1 4,1",
+			"monsterStarts: 4,1; 2,1",
+			"monsters: 2",
+			"OOOOOO",
+			"OP   O",
+			"OOOOOO",
+		}},
+		{"monster count differs from starts", []string{
+			"monsters: 3",
+			"OOOOOO",
+			"O-PM-O",
+			"OOOOOO",
+		}},
+		{"second player", []string{
+			"player2Start: 3,1",
+			"OOOOOO",
+			"OP .MO",
+			"OOOOOO",
+		}},
+		{"second player on a dot", []string{
+			"player2Start: 3,1",
+			"OOOOOO",
+			"OP--MO",
+			"OOOOOO",
+		}},
+		{"explicit single monster", []string{
+			"monsters: 1",
+			"OOOOOO",
+			"OP---O",
+			"O-OO-O",
+			"O----O",
+			"OOOOOO",
+		}},
+		{"no monsters", []string{
+			"monsters: 0",
+			"OOOOO",
+			"OP--O",
+			"OOOOO",
+		}},
+		{"metadata", []string{
+			"name: Tunnels",
+			"material: brick",
+			"speedModifier: 1.25",
+			"steering: shortest",
+			"wrap: both",
+			"parTime: 1m30.5s",
+			"OOO OOO",
+			".P-M--.",
+			"OO.O.OO",
+			"OOO OOO",
+		}},
+		{"custom properties", []string{
+			"name: Credits",
+			"author: Jana",
+			"description: Mind the corners",
+			"difficulty: 3/5",
+			"Seed: 42",
+			"tags=maze, small",
+			"OOOOO",
+			"OP--O",
+			"OOOOO",
+		}},
+		{"empty edges", []string{
+			"OOOOOO",
+			".P--M.",
+			".    .",
+			"OOOOOO",
+		}},
+		{"separator-like row", []string{
+			"OOO",
+			"---",
+			"OPO",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := parseMap(tt.lines)
+			if err != nil {
+				t.Fatalf("Failed to parse map: %v", err)
+			}
+
+			path := filepath.Join(t.TempDir(), "written.txt")
+			file, err := os.Create(path)
+			if err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+			if err := WriteMaps(file, []Map{m, m}); err != nil {
+				t.Fatalf("WriteMaps() error = %v", err)
+			}
+			file.Close()
+
+			reloaded, err := ParseMapsFromFile(path)
+			if err != nil {
+				t.Fatalf("Failed to reparse written map: %v", err)
+			}
+			if len(reloaded) != 2 {
+				t.Fatalf("reparsed %d levels, want 2", len(reloaded))
+			}
+			if !reflect.DeepEqual(reloaded[0], m) {
+				t.Errorf("reparsed map = %+v, want %+v", reloaded[0], m)
+			}
+		})
+	}
+}
+
+func TestWriteMapsRejectsUnwritableMaps(t *testing.T) {
+	m, err := parseMap([]string{
+		"name: x",
+		"OOOO",
+		"OP-O",
+		"OOOO",
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse map: %v", err)
+	}
+	m.Name = "two\nlines"
+
+	var sb strings.Builder
+	if err := WriteMaps(&sb, []Map{m}); err == nil {
+		t.Errorf("WriteMaps() accepted a name with a line break")
+	}
+}
+
+func TestValidateReportsCell(t *testing.T) {
+	m, err := parseMap([]string{
+		"OOOOOO",
+		"O-O--O",
+		"OOOOOO",
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse map: %v", err)
+	}
+	var cellErr *CellError
+	if err := Validate(&m); !errors.As(err, &cellErr) {
+		t.Fatalf("Validate() = %v, want a *CellError", err)
+	}
+	if cellErr.X != 3 || cellErr.Y != 1 {
+		t.Errorf("problem reported at (%d,%d), want (3,1)", cellErr.X, cellErr.Y)
+	}
+}
+
+func TestTrapsFindDeadEnds(t *testing.T) {
+	grid := []string{
+		"OOOOOOO",
+		"O-----O",
+		"O-O-O-O",
+		"O---O-O",
+		"OOOOOOO",
+	}
+	m, err := parseMap(append([]string{"monsters: 1", "playerStart: 1,1"}, grid...))
+	if err != nil {
+		t.Fatalf("Failed to parse map: %v", err)
+	}
+	traps := Traps(&m)
+	if len(traps) != 1 {
+		t.Fatalf("Traps() = %d traps, want 1", len(traps))
+	}
+	trap := traps[0]
+	if got := formatCells(trap.Cells); got != "5,3 5,2 5,1 4,1" {
+		t.Errorf("trap cells = %s, want 5,3 5,2 5,1 4,1", got)
+	}
+	if trap.Exit == nil || *trap.Exit != (StartPos{X: 3, Y: 1}) || trap.Dots != 4 || trap.Start {
+		t.Errorf("trap = %+v, want exit 3,1 with 4 dots and no start", trap)
+	}
+	if err := Validate(&m); err != nil {
+		t.Errorf("Validate() = %v, dots in a dead end should only fail the strict check", err)
+	}
+	var cellErr *CellError
+	if err := ValidateStrict(&m); !errors.As(err, &cellErr) {
+		t.Fatalf("ValidateStrict() = %v, want a *CellError", err)
+	}
+	if cellErr.X != 5 || cellErr.Y != 3 || len(cellErr.Cells) != 3 {
+		t.Errorf("strict problem at (%d,%d) with %v, want (5,3) and the 3 other trap cells", cellErr.X, cellErr.Y, cellErr.Cells)
+	}
+
+	m, err = parseMap(append([]string{"monsters: 1", "playerStart: 5,3"}, grid...))
+	if err != nil {
+		t.Fatalf("Failed to parse map: %v", err)
+	}
+	if err := Validate(&m); !errors.As(err, &cellErr) || !strings.Contains(err.Error(), "5,3 5,2 5,1 4,1") {
+		t.Errorf("Validate() = %v, want the player start trap reported", err)
+	}
+	m, err = parseMap(append([]string{"monsters: 0", "playerStart: 5,3"}, grid...))
+	if err != nil {
+		t.Fatalf("Failed to parse map: %v", err)
+	}
+	if err := ValidateStrict(&m); err != nil {
+		t.Errorf("ValidateStrict() = %v, dead ends are fine without monsters", err)
+	}
+}
+
+func TestLintReportsEveryProblem(t *testing.T) {
+	content := `name: first
+speedModifier: 9
+playerStart: 40,2
+OOOOO
+O---O
+OOOOO
+---
+  OOOOOOO
+  O--O--O
+  OOOOOOO
+---
+OOO
+O-O
+OOO
+`
+	diags, err := LintReader("pack.txt", strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("LintReader() error = %v", err)
+	}
+	var got []string
+	for _, d := range diags {
+		got = append(got, d.String())
+	}
+	want := []string{
+		`pack.txt:2:16: error: invalid speedModifier: "9" (must be between 0.5 and 2.0)`,
+		`pack.txt:3:1: error: playerStart is out of bounds (40,2)`,
+		`pack.txt:5:2: warning: map 'first': level has no loop to escape monsters around; dead end 1,1 2,1 3,1`,
+		`pack.txt:8:3: error: level 2 is 7x3 but level 1 is 5x3; all levels in a file must have the same dimensions`,
+		`pack.txt:9:4: warning: map '': level has no loop to escape monsters around; dead end 1,1 2,1`,
+		`pack.txt:9:7: error: map '': unreachable dot at row 1, col 4`,
+		`pack.txt:9:7: warning: map '': level has no loop to escape monsters around; dead end 4,1 5,1`,
+		`pack.txt:12:1: error: level 3 is 3x3 but level 1 is 5x3; all levels in a file must have the same dimensions`,
+		`pack.txt:13:2: error: map '': player has no escape route; the only open cell is 1,1`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LintReader() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if !HasErrors(diags) || diags[7].Level != 3 {
+		t.Errorf("HasErrors() = %v, level of dimension problem = %d; want true and 3", HasErrors(diags), diags[7].Level)
+	}
+
+	// Sizes are compared with the first level that parsed.
+	diags, err = LintReader("pack.txt", strings.NewReader("name: no grid\n---\nmonsters: 0\nOOOOO\nO---O\nOOOOO\n---\nmonsters: 0\nOOO\nO-O\nOOO\n"))
+	if err != nil {
+		t.Fatalf("LintReader() error = %v", err)
+	}
+	found := false
+	for _, d := range diags {
+		found = found || d.Msg == "level 3 is 3x3 but level 2 is 5x3; all levels in a file must have the same dimensions"
+	}
+	if !found {
+		t.Errorf("LintReader() = %v, want level 3 compared with level 2", diags)
+	}
+}
+
+func TestReadPackFormat2(t *testing.T) {
+	content := `format: 2
+# Practice pack
+pack: Corners
+author: Jana
+version: 1.2
+---
+name: One
+  # comments may be indented
+OOOOOO
+O.P-*O
+O-OO-O
+O----O
+OOOOOO
+---
+name: Two
+OOOOOO
+O1P-.O
+O-OO-O
+O--M1O
+OOOOOO
+`
+	header, levels, err := ReadPack(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("ReadPack() error = %v", err)
+	}
+	want := PackHeader{Format: 2, Name: "Corners", Author: "Jana", Version: "1.2"}
+	if !reflect.DeepEqual(header, want) {
+		t.Errorf("header = %+v, want %+v", header, want)
+	}
+	if len(levels) != 2 || levels[0].Width != 6 || levels[0].Height != 5 {
+		t.Fatalf("levels = %d, want two 6x5 levels", len(levels))
+	}
+	if to := levels[1].Teleports[StartPos{X: 1, Y: 1}]; to != (StartPos{X: 4, Y: 3}) {
+		t.Errorf("teleporter 1 leads to %v, want 4,3", to)
+	}
+	if m := levels[0]; m.Cells[1][1] != Empty || *m.PlayerStart != (StartPos{X: 2, Y: 1}) || m.Cells[1][4] != Energizer {
+		t.Errorf("row 1 parsed as %v with player at %v", m.Cells[1], *m.PlayerStart)
+	}
+
+	var buf strings.Builder
+	if err := WritePack(&buf, header, levels); err != nil {
+		t.Fatalf("WritePack() error = %v", err)
+	}
+	header2, levels2, err := ReadPack(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatalf("ReadPack() of written pack error = %v\n%s", err, buf.String())
+	}
+	if !reflect.DeepEqual(header2, header) || !reflect.DeepEqual(levels2, levels) {
+		t.Errorf("written pack reads back differently:\n%s", buf.String())
+	}
+}
+
+func TestReadPackFormat2Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"ragged row", "format: 2\n---\nOOOO\nOP-O\nOOO\n", "pack.txt:5:4: error: row 3 is 3 cells wide but the first row is 4"},
+		{"blank cell", "format: 2\n---\nOOOO\nOP O\nOOOO\n", "pack.txt:4:3: error: blank in grid row"},
+		{"wide character", "format: 2\n---\nOOOOO\nOPé-O\nOOOOO\n", "pack.txt:4:3: error: unknown grid character 'é'"},
+		{"grid in header", "format: 2\nOOO\n---\nOOOO\nOP-O\nOOOO\n", "pack.txt:2:1: error: file header holds a grid row"},
+		{"no levels", "format: 2\npack: Empty\n", "pack.txt:1:1: error: format 2 file has no levels"},
+		{"unknown format", "format: 3\nOOOO\n", "pack.txt:1:1: error: unsupported map format \"3\""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := ReadPack(strings.NewReader(tt.content)); err == nil {
+				t.Error("ReadPack() accepted the pack")
+			}
+			diags, err := LintReader("pack.txt", strings.NewReader(tt.content))
+			if err != nil {
+				t.Fatalf("LintReader() error = %v", err)
+			}
+			found := false
+			for _, d := range diags {
+				found = found || strings.HasPrefix(d.String(), tt.want)
+			}
+			if !found {
+				t.Errorf("LintReader() = %v, want %q...", diags, tt.want)
+			}
+		})
+	}
+
+	// The same rows load unchanged in the original format.
+	if _, err := ReadMaps(strings.NewReader("OOOO\nOP O\nOOO\n")); err != nil {
+		t.Errorf("ReadMaps() of a format 1 pack error = %v", err)
+	}
+}
+
+func TestReadPackLegend(t *testing.T) {
+	content := `format: 2
+# Imported from a '#' and '.' drawing
+legend: #=wall ═=wall .=dot _=empty o=energizer C=player G=monster
+---
+name: Imported
+═══════
+#C..o.#
+#.##._#
+#...G.#
+#######
+`
+	header, levels, err := ReadPack(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("ReadPack() error = %v", err)
+	}
+	if got := header.Legend.String(); got != "#=wall ═=wall .=dot _=empty o=energizer C=player G=monster" {
+		t.Errorf("legend = %s", got)
+	}
+	m := levels[0]
+	if m.Width != 7 || m.Height != 5 {
+		t.Fatalf("level is %dx%d, want 7x5; rows starting with '#' are grid rows here", m.Width, m.Height)
+	}
+	if m.Cells[0][3] != Wall || m.Cells[1][2] != Dot || m.Cells[1][4] != Energizer || m.Cells[2][5] != Empty {
+		t.Errorf("cells parsed wrongly: %v %v", m.Cells[1], m.Cells[2])
+	}
+	if *m.PlayerStart != (StartPos{X: 1, Y: 1}) || !reflect.DeepEqual(m.MonsterStarts, []StartPos{{X: 4, Y: 3}}) {
+		t.Errorf("starts = %v and %v", *m.PlayerStart, m.MonsterStarts)
+	}
+
+	var buf strings.Builder
+	if err := WritePack(&buf, header, levels); err != nil {
+		t.Fatalf("WritePack() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "\n#C..o.#\n#.##._#\n") {
+		t.Errorf("written grid does not use the legend:\n%s", buf.String())
+	}
+	_, reread, err := ReadPack(strings.NewReader(buf.String()))
+	if err != nil || !reflect.DeepEqual(reread, levels) {
+		t.Errorf("written pack reads back differently (%v):\n%s", err, buf.String())
+	}
+
+	diags, err := LintReader("pack.txt", strings.NewReader(strings.Replace(content, "#.##._#", "#.##. #", 1)))
+	if err != nil {
+		t.Fatalf("LintReader() error = %v", err)
+	}
+	if !HasErrors(diags) || diags[0].String() != "pack.txt:8:6: error: blank in grid row; format 2 marks empty cells with '_'" {
+		t.Errorf("LintReader() = %v, want the blank reported with the legend's empty character", diags)
+	}
+}
+
+func TestParseLegendErrors(t *testing.T) {
+	for _, value := range []string{
+		"#=wall .=dot",               // No empty character
+		"#=wall .=dot _=empty #=dot", // Character listed twice
+		"#=wall .=dot _=empty 1=wall",
+		"#=wall .=dot _=empty :=monster",
+		"#=wall .=dot _=empty x=ghost",
+		"#wall",
+	} {
+		if _, err := parseLegend(value); err == nil {
+			t.Errorf("parseLegend(%q) accepted the legend", value)
+		}
+	}
+	if _, err := parseMap([]string{"legend: #=wall .=dot _=empty", "###", "#.#", "###"}); err == nil {
+		t.Error("expected an error for a legend inside a level")
+	}
+
+	diags, err := LintReader("old.txt", strings.NewReader("legend: #=wall .=dot _=empty\nOOOOO\nO---O\nOOOOO\n"))
+	if err != nil {
+		t.Fatalf("LintReader() error = %v", err)
+	}
+	if len(diags) == 0 || !strings.Contains(diags[0].String(), "old.txt:1:9: error: legends need format 2") {
+		t.Errorf("LintReader() = %v, want a format 1 legend refused", diags)
+	}
+}
@@ -0,0 +1,109 @@
+package maps
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseMapMetaLine splits "key: value" or "key=value" at whichever of ':'
+// and '=' comes first.
+func parseMapMetaLine(line string) (key, value string) {
+	trimmed := strings.TrimSpace(line)
+	idx := strings.IndexAny(trimmed, ":=")
+	if idx == -1 {
+		return "", ""
+	}
+	return strings.TrimSpace(trimmed[:idx]), strings.TrimSpace(trimmed[idx+1:])
+}
+
+// isMetaLine reports whether a level line is metadata. Grid rows never
+// hold ':' or '=', so any line that does is metadata.
+func isMetaLine(line string) bool {
+	return strings.ContainsAny(line, ":=")
+}
+
+// validMetaKey reports whether key can name a metadata entry: a letter
+// followed by letters, digits or '_'.
+func validMetaKey(key string) bool {
+	for i, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && (r >= '0' && r <= '9' || r == '_'):
+		default:
+			return false
+		}
+	}
+	return key != ""
+}
+
+// isKnownKey reports whether the loader reads a level key itself rather
+// than keeping it in Extra.
+func isKnownKey(key string) bool {
+	return strings.EqualFold(key, "monsterStart") || strings.EqualFold(key, "format") || isKnownKeyIn(key, metaKeys)
+}
+
+// isKnownKeyIn reports whether key is one of known, ignoring case.
+func isKnownKeyIn(key string, known []string) bool {
+	for _, k := range known {
+		if strings.EqualFold(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// suggestKey returns the level key a misspelt one most likely meant.
+func suggestKey(key string) string {
+	return suggestKeyFrom(key, metaKeys)
+}
+
+// suggestKeyFrom returns the key of known a misspelt one most likely meant,
+// or "" when none is close enough to be a typo. Short keys allow a single
+// edit, so custom keys like "map" are not taken for "wrap".
+func suggestKeyFrom(key string, knownKeys []string) string {
+	lower := strings.ToLower(key)
+	best, bestDist := "", maxKeyTypoDistance+1
+	for _, known := range knownKeys {
+		limit := maxKeyTypoDistance
+		if len(known) <= 4 {
+			limit = 1
+		}
+		if d := editDistance(lower, strings.ToLower(known)); d <= limit && d < bestDist {
+			best, bestDist = known, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// unknownKeyWarning points out a key that is kept in Extra but looks like
+// a typo of a known one or like the start of a grid row.
+func unknownKeyWarning(key string) string {
+	if s := suggestKey(key); s != "" {
+		return fmt.Sprintf("unknown key %q kept as a custom property; did you mean %q?", key, s)
+	}
+	if strings.Trim(key, gridKeyRunes) == "" {
+		return fmt.Sprintf("unknown key %q kept as a custom property; grid rows cannot contain ':' or '='", key)
+	}
+	return ""
+}
@@ -0,0 +1,245 @@
+package maps
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadPackFromFile reads and validates a map file like LoadMapsFromFile and
+// also returns its header. Files in the original format get a header with
+// Format 1 and nothing else set.
+func LoadPackFromFile(filename string) (PackHeader, []Map, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return PackHeader{}, nil, err
+	}
+	defer file.Close()
+	return ReadPack(file)
+}
+
+// ReadPack reads and validates a map pack from r, like LoadPackFromFile.
+func ReadPack(r io.Reader) (PackHeader, []Map, error) {
+	pack, err := scanPack(r)
+	if err != nil {
+		return PackHeader{}, nil, err
+	}
+	header, err := parseHeader(pack, nil)
+	if err != nil {
+		return PackHeader{}, nil, err
+	}
+	levels, err := loadSections(pack, header.Legend)
+	return header, levels, err
+}
+
+// ParsePackFromFile reads a map file and its header without validating the
+// levels, like ParseMapsFromFile.
+func ParsePackFromFile(filename string) (PackHeader, []Map, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return PackHeader{}, nil, err
+	}
+	defer file.Close()
+	pack, err := scanPack(file)
+	if err != nil {
+		return PackHeader{}, nil, err
+	}
+	header, err := parseHeader(pack, nil)
+	if err != nil {
+		return PackHeader{}, nil, err
+	}
+	levels, err := parseSections(pack, header.Legend)
+	return header, levels, err
+}
+
+// scanPack splits a map file into the non-empty lines of each level,
+// remembering their line numbers. A first line of "format: 2" switches to
+// format 2: '#' lines are comments, unless the legend makes '#' a grid
+// character, and everything before the first "---" is the file header.
+func scanPack(r io.Reader) (scannedPack, error) {
+	pack := scannedPack{format: formatV1}
+	var current numberedSection
+	inHeader, started := false, false
+	formatLine := 0
+	var cellRunes map[rune]bool // Legend characters; '#' among them makes '#' rows grid data
+
+	scanner := bufio.NewScanner(r)
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		if pack.format == formatV2 {
+			line = strings.TrimSuffix(line, "\r")
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" || (strings.HasPrefix(trimmed, "#") && (inHeader || !cellRunes['#'])) {
+				continue
+			}
+			if inHeader {
+				if runes := legendRunes(trimmed); runes != nil {
+					cellRunes = runes
+				}
+			}
+		}
+
+		if !started && strings.TrimSpace(line) != "" {
+			started = true
+			format, ok, err := parseFormatLine(line)
+			if err != nil {
+				return scannedPack{}, fmt.Errorf("line %d: %w", lineNo, &sourceError{line: lineNo, col: 1, msg: err.Error()})
+			}
+			if ok {
+				pack.format, formatLine = format, lineNo
+				if format == formatV2 {
+					inHeader = true
+					pack.header = &numberedSection{}
+				}
+				continue
+			}
+		}
+
+		// Check for level separator
+		if line == "---" {
+			if inHeader {
+				*pack.header = current
+				current = numberedSection{}
+				inHeader = false
+				continue
+			}
+			if len(current.lines) > 0 {
+				pack.sections = append(pack.sections, current)
+				current = numberedSection{}
+			}
+			continue
+		}
+
+		if line != "" {
+			current.lines = append(current.lines, line)
+			current.lineNos = append(current.lineNos, lineNo)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return scannedPack{}, err
+	}
+	if inHeader {
+		return scannedPack{}, fmt.Errorf("line %d: %w", formatLine, &sourceError{line: formatLine, col: 1, msg: "format 2 file has no levels; end the header with ---"})
+	}
+
+	// Don't forget the last map
+	if len(current.lines) > 0 {
+		pack.sections = append(pack.sections, current)
+	}
+	return pack, nil
+}
+
+// parseFormatLine reads a "format: n" line. ok is false for any other line.
+func parseFormatLine(line string) (format int, ok bool, err error) {
+	if !isMetaLine(line) {
+		return 0, false, nil
+	}
+	key, value := parseMapMetaLine(line)
+	if !strings.EqualFold(key, "format") {
+		return 0, false, nil
+	}
+	format, err = strconv.Atoi(value)
+	if err != nil || (format != formatV1 && format != formatV2) {
+		return 0, false, fmt.Errorf("unsupported map format %q (must be %d or %d)", value, formatV1, formatV2)
+	}
+	return format, true, nil
+}
+
+// parseHeader reads the file header of a format 2 pack. Like parseSection
+// it stops at the first problem unless report is set.
+func parseHeader(pack scannedPack, report func(error)) (PackHeader, error) {
+	header := PackHeader{Format: pack.format}
+	if pack.header == nil {
+		return header, nil
+	}
+	for i, line := range pack.header.lines {
+		var err error
+		if !isMetaLine(line) {
+			err = &sourceError{line: i, col: 1, msg: "file header holds a grid row; end the header with ---"}
+		} else {
+			rawKey, value := parseMapMetaLine(line)
+			switch strings.ToLower(rawKey) {
+			case "format":
+				err = &sourceError{line: i, col: 1, msg: "format must be the first line of the file"}
+			case "pack":
+				header.Name = value
+			case "author":
+				header.Author = value
+			case "version":
+				header.Version = value
+			case "legend":
+				legend, lerr := parseLegend(value)
+				if lerr != nil {
+					err = &sourceError{line: i, col: strings.Index(line, value) + 1, msg: lerr.Error()}
+					break
+				}
+				header.Legend = legend
+			default:
+				if !validMetaKey(rawKey) {
+					err = &sourceError{line: i, col: 1, msg: fmt.Sprintf("invalid metadata key %q", rawKey)}
+					break
+				}
+				if header.Extra == nil {
+					header.Extra = make(map[string]string)
+				}
+				header.Extra[rawKey] = value
+				if s := suggestKeyFrom(rawKey, headerKeys); s != "" && report != nil {
+					report(&sourceError{line: i, col: 1, warning: true,
+						msg: fmt.Sprintf("unknown header key %q kept as a custom property; did you mean %q?", rawKey, s)})
+				}
+			}
+		}
+		if err != nil {
+			if report == nil {
+				return PackHeader{}, fmt.Errorf("file header: %v", err)
+			}
+			report(err)
+		}
+	}
+	return header, nil
+}
+
+// WritePack writes levels with header. Format 2 headers give a format 2
+// file, anything else the original format WriteMaps writes.
+func WritePack(w io.Writer, header PackHeader, levels []Map) error {
+	if header.Format != formatV2 {
+		return WriteMaps(w, levels)
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "format: %d\n", formatV2)
+	extraKeys, err := sortedExtraKeys(header.Extra, func(key string) bool {
+		return isKnownKeyIn(key, headerKeys)
+	})
+	if err != nil {
+		return fmt.Errorf("file header: %v", err)
+	}
+	if strings.ContainsAny(header.Name+header.Author+header.Version, "\r\n") {
+		return fmt.Errorf("file header: pack, author and version must fit on one line")
+	}
+	if header.Name != "" {
+		fmt.Fprintf(bw, "pack: %s\n", header.Name)
+	}
+	if header.Author != "" {
+		fmt.Fprintf(bw, "author: %s\n", header.Author)
+	}
+	if header.Version != "" {
+		fmt.Fprintf(bw, "version: %s\n", header.Version)
+	}
+	if header.Legend != nil {
+		fmt.Fprintf(bw, "legend: %s\n", header.Legend)
+	}
+	for _, key := range extraKeys {
+		fmt.Fprintf(bw, "%s: %s\n", key, header.Extra[key])
+	}
+	for i := range levels {
+		fmt.Fprintln(bw, "---")
+		if err := writeMap(bw, &levels[i], formatV2, header.Legend); err != nil {
+			return fmt.Errorf("level %d: %v", i+1, err)
+		}
+	}
+	return bw.Flush()
+}
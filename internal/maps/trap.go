@@ -0,0 +1,207 @@
+package maps
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Traps lists the dead ends of m that hold a player start or dots. Only
+// cells on a loop let the player double back past a monster; everything
+// else hangs off the loops in dead ends, or the whole level is one when it
+// has no loop at all.
+func Traps(m *Map) []Trap {
+	peeled := peelDeadEnds(m)
+	starts := make(map[StartPos]bool)
+	for _, s := range []*StartPos{m.PlayerStart, m.Player2Start} {
+		if s != nil {
+			starts[*s] = true
+		}
+	}
+
+	var traps []Trap
+	seen := make(map[StartPos]bool)
+	for _, first := range peeled.order {
+		if seen[first] {
+			continue
+		}
+		// Collect the pocket the first peeled cell belongs to; peeling
+		// starts at the tips, so that cell is the innermost one.
+		trap := Trap{}
+		seen[first] = true
+		queue := []StartPos{first}
+		for len(queue) > 0 {
+			p := queue[0]
+			queue = queue[1:]
+			trap.Cells = append(trap.Cells, p)
+			trap.Start = trap.Start || starts[p]
+			if m.HasDot(p.X, p.Y) {
+				trap.Dots++
+			}
+			for _, n := range m.openNeighbors(p.X, p.Y) {
+				if !peeled.cells[n] {
+					exit := n
+					trap.Exit = &exit
+					continue
+				}
+				if !seen[n] {
+					seen[n] = true
+					queue = append(queue, n)
+				}
+			}
+		}
+		if trap.Start || trap.Dots > 0 {
+			traps = append(traps, trap)
+		}
+	}
+	return traps
+}
+
+// deadEndCells are the cells peeled off the corridor graph, in peeling
+// order.
+type deadEndCells struct {
+	cells map[StartPos]bool
+	order []StartPos
+}
+
+// peelDeadEnds strips cells with at most one open neighbour until only the
+// loops are left.
+func peelDeadEnds(m *Map) deadEndCells {
+	peeled := deadEndCells{cells: make(map[StartPos]bool)}
+	degree := make(map[StartPos]int)
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if m.IsWall(x, y) {
+				continue
+			}
+			p := StartPos{X: x, Y: y}
+			degree[p] = len(m.openNeighbors(x, y))
+			if degree[p] <= 1 {
+				peeled.cells[p] = true
+				peeled.order = append(peeled.order, p)
+			}
+		}
+	}
+	for i := 0; i < len(peeled.order); i++ {
+		p := peeled.order[i]
+		for _, n := range m.openNeighbors(p.X, p.Y) {
+			if peeled.cells[n] {
+				continue
+			}
+			degree[n]--
+			if degree[n] <= 1 {
+				peeled.cells[n] = true
+				peeled.order = append(peeled.order, n)
+			}
+		}
+	}
+	return peeled
+}
+
+// openNeighbors lists the distinct cells one move away from (x, y).
+func (m *Map) openNeighbors(x, y int) []StartPos {
+	var out []StartPos
+	for _, d := range neighborSteps {
+		nx, ny, ok := m.Neighbor(x, y, d[0], d[1])
+		if !ok || (nx == x && ny == y) {
+			continue
+		}
+		n := StartPos{X: nx, Y: ny}
+		dup := false
+		for _, o := range out {
+			dup = dup || o == n
+		}
+		if !dup {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// hasMonsters reports whether the level spawns any monster.
+func (m *Map) hasMonsters() bool {
+	return m.MonsterCount > 0 || len(m.MonsterStarts) > 0
+}
+
+// checkEscapes reports monster levels where the player cannot get away: a
+// single open cell, or a player start in a dead end off a loop. Corridor
+// levels without any loop are left to ValidateStrict.
+func checkEscapes(m *Map, report func(error) bool) {
+	if !m.hasMonsters() {
+		return
+	}
+	for _, trap := range Traps(m) {
+		if trap.fatal() && !report(trap.problem(m)) {
+			return
+		}
+	}
+}
+
+// checkStrict reports the traps of a monster level that the loader lets
+// through.
+func checkStrict(m *Map, report func(error) bool) {
+	if !m.hasMonsters() {
+		return
+	}
+	for _, trap := range Traps(m) {
+		if trap.fatal() {
+			continue
+		}
+		if !report(trap.problem(m)) {
+			return
+		}
+	}
+}
+
+// ValidateStrict runs Validate and then also refuses monster levels with
+// dots in dead ends or without any loop, which the loader accepts.
+func ValidateStrict(m *Map) error {
+	if err := validateMap(m); err != nil {
+		return err
+	}
+	var first error
+	checkStrict(m, func(err error) bool {
+		first = err
+		return false
+	})
+	return first
+}
+
+// fatal reports whether the loader refuses a monster level over t.
+func (t Trap) fatal() bool {
+	if t.Exit == nil {
+		return len(t.Cells) == 1
+	}
+	return t.Start
+}
+
+// problem describes t as a *CellError covering all of its cells.
+func (t Trap) problem(m *Map) error {
+	switch {
+	case t.Exit == nil && len(t.Cells) == 1:
+		return trapError(t, "map '%s': player has no escape route; the only open cell is %s", m.Name, formatCells(t.Cells))
+	case t.Exit == nil:
+		return trapError(t, "map '%s': level has no loop to escape monsters around; dead end %s", m.Name, formatCells(t.Cells))
+	case t.Start:
+		return trapError(t, "map '%s': player starts in a dead end %s with its only exit at %d,%d", m.Name, formatCells(t.Cells), t.Exit.X, t.Exit.Y)
+	}
+	dots := "dots"
+	if t.Dots == 1 {
+		dots = "dot"
+	}
+	return trapError(t, "map '%s': %d %s in dead end %s with its only exit at %d,%d", m.Name, t.Dots, dots, formatCells(t.Cells), t.Exit.X, t.Exit.Y)
+}
+
+func trapError(trap Trap, format string, args ...any) error {
+	err := cellErrorf(trap.Cells[0].X, trap.Cells[0].Y, format, args...).(*CellError)
+	err.Cells = trap.Cells[1:]
+	return err
+}
+
+// formatCells writes cells as "x,y x,y ...".
+func formatCells(cells []StartPos) string {
+	parts := make([]string, len(cells))
+	for i, c := range cells {
+		parts[i] = fmt.Sprintf("%d,%d", c.X, c.Y)
+	}
+	return strings.Join(parts, " ")
+}
@@ -1,5 +1,7 @@
 package maps
 
+import "time"
+
 type Map struct {
 	Width         int
 	Height        int
@@ -8,8 +10,22 @@ type Map struct {
 	Material      string
 	MonsterCount  int
 	SpeedModifier float64
+	Steering      string
 	PlayerStart   *StartPos
+	Player2Start  *StartPos // Second player in co-op, ignored otherwise
 	MonsterStarts []StartPos
+	MonsterBrains []BrainSpec // Per monster start, in order
+	Wrap          string
+	Teleports     map[StartPos]StartPos // Each teleporter cell maps to its partner
+	ParTime       time.Duration         // Target time for time attack, 0 for none
+	Author        string
+	Description   string
+	Difficulty    string            // Free text such as "easy" or "3/5"
+	Extra         map[string]string // Metadata keys the loader does not know, as written
+
+	// The file left the monster count at its default, so the writer
+	// leaves the monsters key out as well.
+	defaultMonsters bool
 }
 
 type StartPos struct {
@@ -17,8 +33,130 @@ type StartPos struct {
 	Y int
 }
 
+// BrainSpec names a monster personality; Route is only used by patrols.
+type BrainSpec struct {
+	Name  string
+	Route []StartPos
+}
+
+// CellError is a validation problem that can be pointed at on the grid.
+type CellError struct {
+	X     int
+	Y     int
+	Msg   string
+	Cells []StartPos // Further cells the problem covers, e.g. the rest of a trap
+}
+
+// levelMeta collects the metadata keys of a level while it is parsed.
+type levelMeta struct {
+	name            string
+	material        string
+	author          string
+	description     string
+	difficulty      string
+	extra           map[string]string
+	monsterCount    int
+	monsterCountSet bool
+	speedModifier   float64
+	steering        string
+	wrap            string
+	parTime         time.Duration
+	playerStart     *StartPos
+	player2Start    *StartPos
+	monsterStarts   []StartPos
+	monsterBrains   []BrainSpec
+}
+
+// PackHeader describes a map file as a whole. Only format 2 files have a
+// header block; for others just Format is set.
+type PackHeader struct {
+	Format  int
+	Name    string
+	Author  string
+	Version string
+	Legend  Legend            // Grid characters of the file, nil for the built-in ones
+	Extra   map[string]string // Header keys the loader does not know, as written
+}
+
+// Glyph is what a grid character stands for.
+type Glyph int
+
+// LegendEntry maps one grid character to a glyph.
+type LegendEntry struct {
+	Rune  rune
+	Glyph Glyph
+}
+
+// Legend replaces the built-in grid characters of a format 2 pack, in the
+// order the file lists them. Teleporter digits keep their meaning.
+type Legend []LegendEntry
+
+// scannedPack is a map file split into sections before parsing.
+type scannedPack struct {
+	format   int
+	header   *numberedSection // Format 2 header block, nil in format 1
+	sections []numberedSection
+}
+
+// numberedSection holds the non-empty lines of one level with their line
+// numbers in the file.
+type numberedSection struct {
+	lines   []string
+	lineNos []int
+}
+
+// sectionSource maps a parsed level back to the lines of its section.
+type sectionSource struct {
+	rows    []int          // Section line of each grid row
+	indents []int          // Leading blanks trimmed off each grid row
+	meta    map[string]int // Section line of each metadata key, lower case
+}
+
+// sourceError is a problem at a line and 1-based column of a section.
+type sourceError struct {
+	line    int
+	col     int
+	msg     string
+	warning bool // Reported by Lint but never fails a load
+}
+
+// metaError is a problem with the value of a metadata key.
+type metaError struct {
+	key string
+	msg string
+}
+
+// Trap is a dead end holding the player start or dots. A monster coming in
+// behind the player leaves no way past it.
+type Trap struct {
+	Cells []StartPos // Innermost cell first
+	Exit  *StartPos  // Cell on a loop the dead end hangs off, nil when the level has no loop
+	Start bool       // A player start lies inside
+	Dots  int
+}
+
+// Severity tells errors, which stop a map from loading, from warnings.
+type Severity string
+
+// Diagnostic is one problem Lint found, at a 1-based line and column of the
+// file.
+type Diagnostic struct {
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Col      int      `json:"col"`
+	Level    int      `json:"level"` // 1-based level the problem belongs to, 0 for the whole file
+	Severity Severity `json:"severity"`
+	Msg      string   `json:"message"`
+}
+
 // Creature represents anything with X, Y coordinates (used for rendering)
 type Creature interface {
 	GetX() int
 	GetY() int
 }
+
+// frightenable is implemented by creatures that can be frightened; Render
+// draws them in blue while they are.
+type frightenable interface {
+	IsFrightened() bool
+}
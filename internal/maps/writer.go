@@ -0,0 +1,249 @@
+package maps
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WriteMaps writes levels in the TXT format LoadMapsFromFile reads, one
+// "---" separated section per level in the given order. Parsing the output
+// gives back the same levels: starts are written as grid letters where that
+// is lossless and as metadata otherwise.
+func WriteMaps(w io.Writer, levels []Map) error {
+	bw := bufio.NewWriter(w)
+	for i := range levels {
+		if i > 0 {
+			fmt.Fprintln(bw, "---")
+		}
+		if err := writeMap(bw, &levels[i], formatV1, nil); err != nil {
+			return fmt.Errorf("level %d: %v", i+1, err)
+		}
+	}
+	return bw.Flush()
+}
+
+// writeMap writes one level in the given format, drawing the grid with
+// legend or, when it is nil, the built-in characters.
+func writeMap(w io.Writer, m *Map, format int, legend Legend) error {
+	glyphs := gridGlyphs(format, legend)
+	if len(m.Teleports) > 0 && format != formatV2 {
+		return fmt.Errorf("teleporters need a format 2 pack")
+	}
+	if len(m.Teleports) > 2*maxTeleporterPairs {
+		return fmt.Errorf("%d teleporter pairs, the format has digits for %d", len(m.Teleports)/2, maxTeleporterPairs)
+	}
+	if strings.ContainsAny(m.Name+m.Material+m.Author+m.Description+m.Difficulty, "\r\n") {
+		return fmt.Errorf("name, material, author, description and difficulty must fit on one line")
+	}
+	extraKeys, err := sortedExtraKeys(m.Extra, isKnownKey)
+	if err != nil {
+		return err
+	}
+
+	if m.Name != "" {
+		fmt.Fprintf(w, "name: %s\n", m.Name)
+	}
+	if m.Material != "" {
+		fmt.Fprintf(w, "material: %s\n", m.Material)
+	}
+	if m.Author != "" {
+		fmt.Fprintf(w, "author: %s\n", m.Author)
+	}
+	if m.Description != "" {
+		fmt.Fprintf(w, "description: %s\n", m.Description)
+	}
+	if m.Difficulty != "" {
+		fmt.Fprintf(w, "difficulty: %s\n", m.Difficulty)
+	}
+	for _, key := range extraKeys {
+		fmt.Fprintf(w, "%s: %s\n", key, m.Extra[key])
+	}
+	if m.SpeedModifier != 0 && m.SpeedModifier != 1 {
+		fmt.Fprintf(w, "speedModifier: %s\n", strconv.FormatFloat(m.SpeedModifier, 'g', -1, 64))
+	}
+	if m.Steering != "" && m.Steering != SteeringGreedy {
+		fmt.Fprintf(w, "steering: %s\n", m.Steering)
+	}
+	if m.Wrap != "" && m.Wrap != WrapNone {
+		fmt.Fprintf(w, "wrap: %s\n", m.Wrap)
+	}
+	if m.ParTime > 0 {
+		fmt.Fprintf(w, "parTime: %s\n", m.ParTime)
+	}
+	if len(m.MonsterBrains) > 0 {
+		fmt.Fprintf(w, "monsterBrains: %s\n", formatBrainList(m.MonsterBrains))
+	}
+
+	// Starts go into the grid as letters when the letter loses nothing, that
+	// is on an empty cell. Grid monsters also fix the monster count and are
+	// read back in row order, which decides their brains.
+	playerRune, playerOK := glyphs.runeFor(GlyphPlayer)
+	player2Rune, player2OK := glyphs.runeFor(GlyphPlayer2)
+	monsterRune, monsterOK := glyphs.runeFor(GlyphMonster)
+	playerInGrid := playerOK && m.PlayerStart != nil && m.letterFits(*m.PlayerStart)
+	player2InGrid := player2OK && m.Player2Start != nil && m.letterFits(*m.Player2Start) &&
+		(m.PlayerStart == nil || *m.Player2Start != *m.PlayerStart)
+	monstersInGrid := monsterOK && len(m.MonsterStarts) > 0 && m.MonsterCount == len(m.MonsterStarts)
+	for i, pos := range m.MonsterStarts {
+		monstersInGrid = monstersInGrid && m.letterFits(pos)
+		if m.PlayerStart != nil && pos == *m.PlayerStart {
+			monstersInGrid = false
+		}
+		if m.Player2Start != nil && pos == *m.Player2Start {
+			monstersInGrid = false
+		}
+		if i > 0 && !rowMajorBefore(m.MonsterStarts[i-1], pos) {
+			monstersInGrid = false
+		}
+	}
+	if m.PlayerStart != nil && !playerInGrid {
+		fmt.Fprintf(w, "playerStart: %d,%d\n", m.PlayerStart.X, m.PlayerStart.Y)
+	}
+	if m.Player2Start != nil && !player2InGrid {
+		fmt.Fprintf(w, "player2Start: %d,%d\n", m.Player2Start.X, m.Player2Start.Y)
+	}
+	if !monstersInGrid {
+		if len(m.MonsterStarts) > 0 {
+			fmt.Fprintf(w, "monsterStarts: %s\n", formatStartList(m.MonsterStarts))
+		}
+		if !m.defaultMonsters || m.MonsterCount != 1 {
+			fmt.Fprintf(w, "monsters: %d\n", m.MonsterCount)
+		}
+	}
+
+	letters := make(map[StartPos]rune)
+	nextPair := '1'
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			pos := StartPos{X: x, Y: y}
+			if partner, ok := m.Teleports[pos]; ok && letters[pos] == 0 {
+				letters[pos] = nextPair
+				letters[partner] = nextPair
+				nextPair++
+			}
+		}
+	}
+	if playerInGrid {
+		letters[*m.PlayerStart] = playerRune
+	}
+	if player2InGrid {
+		letters[*m.Player2Start] = player2Rune
+	}
+	if monstersInGrid {
+		for _, pos := range m.MonsterStarts {
+			letters[pos] = monsterRune
+		}
+	}
+
+	// Cell glyphs share their indices with Cell.
+	var cellRunes [Energizer + 1]rune
+	for c := range cellRunes {
+		r, ok := glyphs.runeFor(Glyph(c))
+		if !ok && m.hasCell(Cell(c)) {
+			return fmt.Errorf("legend has no character for %s", glyphNames[c])
+		}
+		cellRunes[c] = r
+	}
+
+	row := make([]rune, m.Width)
+	for y := 0; y < m.Height; y++ {
+		for x := range row {
+			if ch, ok := letters[StartPos{X: x, Y: y}]; ok {
+				row[x] = ch
+				continue
+			}
+			row[x] = cellRunes[m.Cells[y][x]]
+			// The format 1 parser trims rows, so only empty cells at
+			// either end need a visible character to keep their column.
+			if m.Cells[y][x] == Empty && format == formatV1 && x > 0 && x < m.Width-1 {
+				row[x] = ' '
+			}
+		}
+		// A row of three dots would read as a level separator; the parser
+		// trims the trailing space again. Format 2 keeps spaces, so there
+		// is no way to write it.
+		if string(row) == "---" {
+			if format == formatV2 {
+				return fmt.Errorf("row %d reads as a level separator in format %d", y, formatV2)
+			}
+			fmt.Fprintf(w, "%s \n", string(row))
+			continue
+		}
+		fmt.Fprintf(w, "%s\n", string(row))
+	}
+	return nil
+}
+
+// sortedExtraKeys checks custom keys before they are written and sorts
+// them; known reports keys that would be read back as something else.
+func sortedExtraKeys(extra map[string]string, known func(string) bool) ([]string, error) {
+	keys := make([]string, 0, len(extra))
+	for key, value := range extra {
+		if !validMetaKey(key) {
+			return nil, fmt.Errorf("custom key %q is not a valid metadata key", key)
+		}
+		if known(key) {
+			return nil, fmt.Errorf("custom key %q would be read back as a known key", key)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("value of custom key %q must fit on one line", key)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// hasCell reports whether any cell of m is c.
+func (m *Map) hasCell(c Cell) bool {
+	for _, row := range m.Cells {
+		for _, cell := range row {
+			if cell == c {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rowMajorBefore reports whether a comes strictly before b when the grid is
+// read row by row.
+func rowMajorBefore(a, b StartPos) bool {
+	return a.Y < b.Y || (a.Y == b.Y && a.X < b.X)
+}
+
+// letterFits reports whether a start letter can stand at pos without
+// hiding the cell underneath.
+func (m *Map) letterFits(pos StartPos) bool {
+	if pos.X < 0 || pos.Y < 0 || pos.X >= m.Width || pos.Y >= m.Height {
+		return false
+	}
+	if _, ok := m.Teleports[pos]; ok {
+		return false
+	}
+	return m.Cells[pos.Y][pos.X] == Empty
+}
+
+func formatStartList(list []StartPos) string {
+	parts := make([]string, len(list))
+	for i, pos := range list {
+		parts[i] = fmt.Sprintf("%d,%d", pos.X, pos.Y)
+	}
+	return strings.Join(parts, "; ")
+}
+
+func formatBrainList(specs []BrainSpec) string {
+	parts := make([]string, len(specs))
+	for i, spec := range specs {
+		fields := []string{spec.Name}
+		for _, wp := range spec.Route {
+			fields = append(fields, fmt.Sprintf("%d,%d", wp.X, wp.Y))
+		}
+		parts[i] = strings.Join(fields, " ")
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,124 @@
+package netplay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/sjiamnocna/gopucha/internal/actors"
+	"github.com/sjiamnocna/gopucha/internal/gameplay"
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+// Join connects to a server at addr ("host:port") and takes a free seat.
+func Join(addr string) (*Client, error) {
+	conn, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	c, err := greet(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// greet says hello and builds the mirrored game from the welcome.
+func greet(conn net.Conn) (*Client, error) {
+	c := &Client{conn: conn, enc: json.NewEncoder(conn), dec: json.NewDecoder(conn)}
+	if err := c.enc.Encode(message{Type: msgHello, Version: protocolVersion}); err != nil {
+		return nil, err
+	}
+
+	var welcome message
+	if err := c.dec.Decode(&welcome); err != nil {
+		return nil, err
+	}
+	if welcome.Type == msgError {
+		return nil, errors.New(welcome.Error)
+	}
+	if welcome.Type != msgWelcome {
+		return nil, fmt.Errorf("unexpected %q message from server", welcome.Type)
+	}
+
+	levels, err := maps.ReadMaps(strings.NewReader(welcome.Maps))
+	if err != nil {
+		return nil, fmt.Errorf("server sent invalid maps: %v", err)
+	}
+	if welcome.Players == 2 {
+		c.game = gameplay.NewCoOpGame(levels, welcome.NoMonsters, 0)
+	} else {
+		c.game = gameplay.NewGameWithSeed(levels, welcome.NoMonsters, 0)
+	}
+	if c.game == nil {
+		return nil, fmt.Errorf("failed to create game")
+	}
+
+	c.Seat = welcome.Seat
+	c.Players = welcome.Players
+	c.TickInterval = time.Duration(welcome.TickMillis) * time.Millisecond
+	c.phase = PhaseWaiting
+	return c, nil
+}
+
+// Game is the mirror of the server's game. Draw it, but do not update it.
+func (c *Client) Game() *gameplay.Game {
+	return c.game
+}
+
+// Steer asks the server to turn this client's player.
+func (c *Client) Steer(d actors.Direction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.enc.Encode(message{Type: msgInput, Tick: c.tick, Dir: d.String()})
+}
+
+// Next waits for the server's next tick and applies it to the game.
+func (c *Client) Next() error {
+	var msg message
+	if err := c.dec.Decode(&msg); err != nil {
+		return err
+	}
+	switch {
+	case msg.Type == msgError:
+		return errors.New(msg.Error)
+	case msg.Type != msgState || msg.State == nil:
+		return fmt.Errorf("unexpected %q message from server", msg.Type)
+	}
+	if err := applyState(c.game, msg.State); err != nil {
+		return err
+	}
+	online := make([]bool, 0, len(msg.State.Players))
+	for _, p := range msg.State.Players {
+		online = append(online, p.Online)
+	}
+	c.mu.Lock()
+	c.tick = msg.State.Tick
+	c.phase = msg.State.Phase
+	c.online = online
+	c.mu.Unlock()
+	return nil
+}
+
+// Phase is the server's phase as of the last state.
+func (c *Client) Phase() Phase {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.phase
+}
+
+// Online reports which seats are taken, as of the last state. Next never
+// changes the returned slice.
+func (c *Client) Online() []bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.online
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,34 @@
+package netplay
+
+import "time"
+
+const (
+	DefaultPort         = 7777
+	DefaultTickInterval = 150 * time.Millisecond
+	DefaultCountdown    = 3 * time.Second
+
+	protocolVersion   = 1
+	maxPlayers        = 2
+	bustPauseDuration = 1 * time.Second
+	levelPauseTicks   = 2
+	dialTimeout       = 5 * time.Second
+	inputBuffer       = 64
+)
+
+// Message types on the wire.
+const (
+	msgHello   = "hello"
+	msgWelcome = "welcome"
+	msgInput   = "input"
+	msgState   = "state"
+	msgError   = "error"
+)
+
+const (
+	PhaseWaiting       Phase = "waiting"   // Seats still empty
+	PhaseCountdown     Phase = "countdown" // Level about to start
+	PhasePlaying       Phase = "playing"
+	PhaseLevelComplete Phase = "levelComplete" // Short pause before the next level
+	PhaseGameOver      Phase = "gameOver"
+	PhaseWon           Phase = "won"
+)
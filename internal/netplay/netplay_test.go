@@ -0,0 +1,119 @@
+package netplay
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjiamnocna/gopucha/internal/actors"
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+const testMap = `playerStart: 1,1
+OOOOOOOOOO
+O--------O
+O-OO-OOO-O
+O--------O
+O-OOO-OO-O
+O--------O
+OOOOOOOOOO
+`
+
+// startServer runs a server on a loopback port and stops it when the test
+// ends.
+func startServer(t *testing.T, players int) string {
+	t.Helper()
+	levels, err := maps.ReadMaps(strings.NewReader(testMap))
+	if err != nil {
+		t.Fatalf("ReadMaps() error = %v", err)
+	}
+	srv, err := NewServer(ServerConfig{
+		Maps:            levels,
+		Players:         players,
+		DisableMonsters: true,
+		Seed:            1,
+		TickInterval:    5 * time.Millisecond,
+		Countdown:       20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ln) }()
+	t.Cleanup(func() {
+		ln.Close()
+		if err := <-done; err != nil {
+			t.Errorf("Serve() error = %v", err)
+		}
+	})
+	return ln.Addr().String()
+}
+
+func join(t *testing.T, addr string) *Client {
+	t.Helper()
+	c, err := Join(addr)
+	if err != nil {
+		t.Fatalf("Join() error = %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// waitFor reads states until ok holds, failing after a few hundred ticks.
+func waitFor(t *testing.T, c *Client, what string, ok func() bool) {
+	t.Helper()
+	for i := 0; i < 500; i++ {
+		if err := c.Next(); err != nil {
+			t.Fatalf("Next() error while waiting for %s: %v", what, err)
+		}
+		if ok() {
+			return
+		}
+	}
+	t.Fatalf("gave up waiting for %s", what)
+}
+
+func TestClientMirrorsServerGame(t *testing.T) {
+	c := join(t, startServer(t, 1))
+	if c.Seat != 1 || c.Players != 1 || c.TickInterval != 5*time.Millisecond {
+		t.Fatalf("welcome = seat %d of %d, tick %v", c.Seat, c.Players, c.TickInterval)
+	}
+	game := c.Game()
+	dots := game.CurrentMap.CountDots()
+
+	waitFor(t, c, "countdown", func() bool { return c.Phase() == PhaseCountdown })
+	if err := c.Steer(actors.Down); err != nil {
+		t.Fatalf("Steer() error = %v", err)
+	}
+	waitFor(t, c, "the player to go down", func() bool { return game.Player.Y > 1 })
+	if game.Player.X != 1 || game.Player.Direction != actors.Down {
+		t.Errorf("player at %d,%d heading %v, want column 1 heading down", game.Player.X, game.Player.Y, game.Player.Direction)
+	}
+	if game.CurrentMap.CountDots() >= dots || game.Score == 0 {
+		t.Errorf("dots %d of %d, score %d: eaten dots did not reach the client", game.CurrentMap.CountDots(), dots, game.Score)
+	}
+}
+
+func TestServerWaitsForEverySeat(t *testing.T) {
+	addr := startServer(t, 2)
+	first := join(t, addr)
+	waitFor(t, first, "a state", func() bool { return true })
+	if online := first.Online(); first.Phase() != PhaseWaiting || len(online) != 2 || !online[0] || online[1] {
+		t.Fatalf("alone: phase %s, online %v", first.Phase(), online)
+	}
+
+	second := join(t, addr)
+	if second.Seat != 2 || !second.Game().CoOp {
+		t.Errorf("second player got seat %d, co-op %v", second.Seat, second.Game().CoOp)
+	}
+	waitFor(t, first, "the countdown", func() bool { return first.Phase() == PhaseCountdown })
+
+	if _, err := Join(addr); err == nil || !strings.Contains(err.Error(), "full") {
+		t.Errorf("third Join() error = %v, want game is full", err)
+	}
+}
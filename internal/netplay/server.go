@@ -0,0 +1,280 @@
+package netplay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/sjiamnocna/gopucha/internal/actors"
+	"github.com/sjiamnocna/gopucha/internal/gameplay"
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+// NewServer sets up the game of cfg; Serve starts it once every seat is
+// taken.
+func NewServer(cfg ServerConfig) (*Server, error) {
+	if cfg.Players < 1 || cfg.Players > maxPlayers {
+		return nil, fmt.Errorf("invalid player count %d (1 to %d)", cfg.Players, maxPlayers)
+	}
+	if cfg.TickInterval <= 0 {
+		cfg.TickInterval = DefaultTickInterval
+	}
+	if cfg.Countdown < 0 {
+		cfg.Countdown = 0
+	}
+
+	var game *gameplay.Game
+	if cfg.Players == 2 {
+		game = gameplay.NewCoOpGame(cfg.Maps, cfg.DisableMonsters, cfg.Seed)
+	} else {
+		game = gameplay.NewGameWithSeed(cfg.Maps, cfg.DisableMonsters, cfg.Seed)
+	}
+	if game == nil {
+		return nil, fmt.Errorf("failed to create game")
+	}
+	game.BustPauseTicks = max(int(bustPauseDuration/cfg.TickInterval), 1)
+
+	var pack bytes.Buffer
+	if err := maps.WriteMaps(&pack, cfg.Maps); err != nil {
+		return nil, err
+	}
+
+	return &Server{
+		cfg:     cfg,
+		mapText: pack.String(),
+		game:    game,
+		phase:   PhaseWaiting,
+		inputs:  make(chan input, inputBuffer),
+		seats:   make([]*seat, cfg.Players),
+	}, nil
+}
+
+// Serve accepts players on ln and runs the game until it is over or ln is
+// closed. Players who leave can be replaced by new ones; their Pampuch
+// stands still in the meantime.
+func (s *Server) Serve(ln net.Listener) error {
+	acceptErr := make(chan error, 1)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				acceptErr <- err
+				return
+			}
+			go s.handle(conn)
+		}
+	}()
+
+	ticker := time.NewTicker(s.cfg.TickInterval)
+	defer ticker.Stop()
+	defer s.closeSeats()
+
+	for {
+		select {
+		case err := <-acceptErr:
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		case <-ticker.C:
+		}
+
+		s.step()
+		s.broadcast(message{Type: msgState, State: captureState(s.game, s.phase, s.online())})
+		if s.phase == PhaseGameOver || s.phase == PhaseWon {
+			ln.Close()
+			return nil
+		}
+	}
+}
+
+// step applies the inputs received since the last tick and advances the
+// phase or the game by one tick.
+func (s *Server) step() {
+	for drained := false; !drained; {
+		select {
+		case in := <-s.inputs:
+			s.apply(in)
+		default:
+			drained = true
+		}
+	}
+
+	switch s.phase {
+	case PhaseWaiting:
+		if s.seatsTaken() {
+			s.startCountdown()
+		}
+	case PhaseCountdown:
+		s.phaseLeft--
+		if s.phaseLeft <= 0 {
+			s.phase = PhasePlaying
+		}
+	case PhaseLevelComplete:
+		s.phaseLeft--
+		if s.phaseLeft <= 0 {
+			s.game.AdvanceLevel()
+			s.levelTick = s.game.Tick
+			s.startCountdown()
+		}
+	case PhasePlaying:
+		s.game.Update()
+		if s.game.LevelCompleted {
+			s.game.LevelCompleted = false
+			if s.game.CurrentLevel+1 >= len(s.game.Maps) {
+				s.game.Won = true
+			} else {
+				s.phase = PhaseLevelComplete
+				s.phaseLeft = levelPauseTicks
+			}
+		}
+		if s.game.GameOver {
+			s.phase = PhaseGameOver
+		} else if s.game.Won {
+			s.phase = PhaseWon
+		}
+	}
+}
+
+func (s *Server) startCountdown() {
+	s.phase = PhaseCountdown
+	s.phaseLeft = int(s.cfg.Countdown / s.cfg.TickInterval)
+}
+
+// apply steers a seat's player. Turns sent during the level pause or for
+// an earlier level would act on the wrong board, so they are dropped.
+func (s *Server) apply(in input) {
+	if s.phase == PhaseLevelComplete || in.msg.Tick < s.levelTick {
+		return
+	}
+	d, err := actors.ParseDirection(in.msg.Dir)
+	if err != nil {
+		return
+	}
+	gamePlayers(s.game)[in.seat].SetDirection(d)
+}
+
+// handle greets a new connection, gives it a free seat and reads its
+// inputs until it leaves.
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	dec := json.NewDecoder(conn)
+	enc := json.NewEncoder(conn)
+
+	var hello message
+	if err := dec.Decode(&hello); err != nil || hello.Type != msgHello {
+		return
+	}
+	if hello.Version != protocolVersion {
+		enc.Encode(message{Type: msgError, Error: fmt.Sprintf("client speaks protocol %d, server %d", hello.Version, protocolVersion)})
+		return
+	}
+
+	idx := s.takeSeat(&seat{conn: conn, enc: enc})
+	if idx < 0 {
+		enc.Encode(message{Type: msgError, Error: "game is full"})
+		return
+	}
+	defer s.leaveSeat(idx, conn)
+
+	for {
+		var msg message
+		if err := dec.Decode(&msg); err != nil {
+			return
+		}
+		if msg.Type != msgInput {
+			continue
+		}
+		select {
+		case s.inputs <- input{seat: idx, msg: msg}:
+		default:
+			// A client flooding turns loses the excess.
+		}
+	}
+}
+
+// takeSeat puts st on the first free seat and welcomes it there. It
+// returns the seat index, or -1 when every seat is taken.
+func (s *Server) takeSeat(st *seat) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, taken := range s.seats {
+		if taken != nil {
+			continue
+		}
+		err := st.enc.Encode(message{
+			Type:       msgWelcome,
+			Seat:       i + 1,
+			Players:    s.cfg.Players,
+			TickMillis: s.cfg.TickInterval.Milliseconds(),
+			NoMonsters: s.cfg.DisableMonsters,
+			Maps:       s.mapText,
+		})
+		if err != nil {
+			return -1
+		}
+		s.seats[i] = st
+		return i
+	}
+	return -1
+}
+
+func (s *Server) leaveSeat(idx int, conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st := s.seats[idx]; st != nil && st.conn == conn {
+		s.seats[idx] = nil
+	}
+}
+
+func (s *Server) seatsTaken() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, st := range s.seats {
+		if st == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) online() []bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	online := make([]bool, len(s.seats))
+	for i, st := range s.seats {
+		online[i] = st != nil
+	}
+	return online
+}
+
+// broadcast sends msg to every seat. A player whose connection fails is
+// dropped from the seat.
+func (s *Server) broadcast(msg message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, st := range s.seats {
+		if st == nil {
+			continue
+		}
+		st.conn.SetWriteDeadline(time.Now().Add(s.cfg.TickInterval * 4))
+		if err := st.enc.Encode(msg); err != nil {
+			st.conn.Close()
+			s.seats[i] = nil
+		}
+	}
+}
+
+func (s *Server) closeSeats() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, st := range s.seats {
+		if st != nil {
+			st.conn.Close()
+			s.seats[i] = nil
+		}
+	}
+}
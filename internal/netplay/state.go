@@ -0,0 +1,139 @@
+package netplay
+
+import (
+	"fmt"
+
+	"github.com/sjiamnocna/gopucha/internal/actors"
+	"github.com/sjiamnocna/gopucha/internal/gameplay"
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+// captureState describes g for the players. online lists the taken seats.
+func captureState(g *gameplay.Game, phase Phase, online []bool) *State {
+	s := &State{
+		Tick:       g.Tick,
+		Phase:      phase,
+		Level:      g.CurrentLevel,
+		Dots:       dotRows(g.CurrentMap),
+		BustPaused: g.BustPaused,
+		LifeLost:   g.LifeLost,
+	}
+	for i, p := range gamePlayers(g) {
+		ps := PlayerState{X: p.X, Y: p.Y, Dir: p.Direction.String(), Online: i < len(online) && online[i]}
+		if i == 0 {
+			ps.Score, ps.Lives, ps.Ate, ps.Energizer = g.Score, g.Lives, g.DotEaten, g.EnergizerEaten
+		} else {
+			ps.Score, ps.Lives, ps.Ate, ps.Energizer = g.Score2, g.Lives2, g.DotEaten2, g.EnergizerEaten2
+		}
+		s.Players = append(s.Players, ps)
+	}
+	for _, mo := range g.Monsters {
+		s.Monsters = append(s.Monsters, MonsterState{X: mo.X, Y: mo.Y, Dir: mo.Direction.String(), Frightened: mo.Frightened})
+	}
+	return s
+}
+
+// applyState makes g look like s. g has to be built from the same levels
+// and player count as the server's game.
+func applyState(g *gameplay.Game, s *State) error {
+	if s.Level < 0 || s.Level >= len(g.Maps) {
+		return fmt.Errorf("level %d out of range (pack has %d levels)", s.Level+1, len(g.Maps))
+	}
+	if s.Level != g.CurrentLevel {
+		g.CurrentLevel = s.Level
+		g.CurrentMap = &g.Maps[s.Level]
+		g.CurrentSpeedModifier = g.CurrentMap.SpeedModifier
+	}
+	if err := applyDots(g.CurrentMap, s.Dots); err != nil {
+		return err
+	}
+
+	players := gamePlayers(g)
+	if len(s.Players) != len(players) {
+		return fmt.Errorf("state has %d players, game has %d", len(s.Players), len(players))
+	}
+	for i, ps := range s.Players {
+		dir, err := actors.ParseDirection(ps.Dir)
+		if err != nil {
+			return err
+		}
+		players[i].X, players[i].Y, players[i].Direction = ps.X, ps.Y, dir
+		if i == 0 {
+			g.Score, g.Lives, g.DotEaten, g.EnergizerEaten = ps.Score, ps.Lives, ps.Ate, ps.Energizer
+		} else {
+			g.Score2, g.Lives2, g.DotEaten2, g.EnergizerEaten2 = ps.Score, ps.Lives, ps.Ate, ps.Energizer
+		}
+	}
+
+	monsters := make([]actors.Monster, 0, len(s.Monsters))
+	for _, ms := range s.Monsters {
+		dir, err := actors.ParseDirection(ms.Dir)
+		if err != nil {
+			return err
+		}
+		mo := actors.NewMonster(ms.X, ms.Y, dir)
+		mo.Frightened = ms.Frightened
+		monsters = append(monsters, *mo)
+	}
+	g.Monsters = monsters
+
+	g.Tick = s.Tick
+	g.BustPaused = s.BustPaused
+	g.LifeLost = s.LifeLost
+	g.GameOver = s.Phase == PhaseGameOver
+	g.Won = s.Phase == PhaseWon
+	return nil
+}
+
+// gamePlayers lists the game's players by seat, out ones included.
+func gamePlayers(g *gameplay.Game) []*actors.Player {
+	if g.CoOp {
+		return []*actors.Player{g.Player, g.Player2}
+	}
+	return []*actors.Player{g.Player}
+}
+
+// dotRows encodes the dots left on m like a save file does.
+func dotRows(m *maps.Map) []string {
+	rows := make([]string, m.Height)
+	for y := range rows {
+		row := make([]byte, m.Width)
+		for x := range row {
+			switch m.Cells[y][x] {
+			case maps.Dot:
+				row[x] = '-'
+			case maps.Energizer:
+				row[x] = '*'
+			default:
+				row[x] = '.'
+			}
+		}
+		rows[y] = string(row)
+	}
+	return rows
+}
+
+func applyDots(m *maps.Map, rows []string) error {
+	if len(rows) != m.Height {
+		return fmt.Errorf("state has %d rows, map has %d", len(rows), m.Height)
+	}
+	for y, row := range rows {
+		if len(row) != m.Width {
+			return fmt.Errorf("state row %d has %d cells, map has %d", y+1, len(row), m.Width)
+		}
+		for x := 0; x < m.Width; x++ {
+			if m.IsWall(x, y) {
+				continue
+			}
+			switch row[x] {
+			case '-':
+				m.Cells[y][x] = maps.Dot
+			case '*':
+				m.Cells[y][x] = maps.Energizer
+			default:
+				m.Cells[y][x] = maps.Empty
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package netplay
+
+import (
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/sjiamnocna/gopucha/internal/gameplay"
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+// Phase says what the server's game loop is doing.
+type Phase string
+
+// ServerConfig describes a hosted game.
+type ServerConfig struct {
+	Maps            []maps.Map
+	Players         int // Seats to fill before the game starts, 1 or 2
+	DisableMonsters bool
+	Seed            int64
+	TickInterval    time.Duration
+	Countdown       time.Duration // Pause before each level
+}
+
+// Server runs the authoritative game and streams its state to the players.
+type Server struct {
+	cfg       ServerConfig
+	mapText   string // Level pack in map format, sent to joining players
+	game      *gameplay.Game
+	phase     Phase
+	phaseLeft int // Ticks until the countdown or level pause ends
+	levelTick int // Tick the current level started on; older inputs are dropped
+	inputs    chan input
+	mu        sync.Mutex // Protects seats
+	seats     []*seat
+}
+
+// seat is a connected player; seats[0] steers Game.Player.
+type seat struct {
+	conn net.Conn
+	enc  *json.Encoder
+}
+
+type input struct {
+	seat int
+	msg  message
+}
+
+// Client is a joined player. Its game mirrors the server's and only
+// changes in Next.
+type Client struct {
+	Seat         int // 1 for player one, 2 for player two
+	Players      int
+	TickInterval time.Duration
+	game         *gameplay.Game
+	conn         net.Conn
+	dec          *json.Decoder
+	mu           sync.Mutex // Protects enc, tick, phase and online
+	enc          *json.Encoder
+	tick         int // Newest tick seen, sent along with inputs
+	phase        Phase
+	online       []bool // Which seats are taken, as of the last state
+}
+
+// message is one JSON line on the wire; Type says which fields are set.
+type message struct {
+	Type       string `json:"type"`
+	Version    int    `json:"version,omitempty"`
+	Seat       int    `json:"seat,omitempty"`
+	Players    int    `json:"players,omitempty"`
+	TickMillis int64  `json:"tickMillis,omitempty"`
+	NoMonsters bool   `json:"noMonsters,omitempty"`
+	Maps       string `json:"maps,omitempty"`
+	Tick       int    `json:"tick,omitempty"` // Input: last tick the client saw
+	Dir        string `json:"dir,omitempty"`
+	State      *State `json:"state,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// State is the game as every player should draw it after one tick.
+type State struct {
+	Tick       int            `json:"tick"`
+	Phase      Phase          `json:"phase"`
+	Level      int            `json:"level"`
+	Dots       []string       `json:"dots"` // '-' dot, '*' energizer, '.' anything else
+	Players    []PlayerState  `json:"players"`
+	Monsters   []MonsterState `json:"monsters"`
+	BustPaused bool           `json:"bustPaused,omitempty"`
+	LifeLost   bool           `json:"lifeLost,omitempty"`
+}
+
+type PlayerState struct {
+	X         int    `json:"x"`
+	Y         int    `json:"y"`
+	Dir       string `json:"dir"`
+	Score     int    `json:"score"`
+	Lives     int    `json:"lives"`
+	Ate       bool   `json:"ate,omitempty"`
+	Energizer bool   `json:"energizer,omitempty"`
+	Online    bool   `json:"online,omitempty"`
+}
+
+type MonsterState struct {
+	X          int    `json:"x"`
+	Y          int    `json:"y"`
+	Dir        string `json:"dir"`
+	Frightened int    `json:"frightened,omitempty"`
+}
@@ -0,0 +1,7 @@
+package replay
+
+const (
+	formatVersion = 1
+	headerMagic   = "gopucha-replay"
+	inputsMarker  = "---"
+)
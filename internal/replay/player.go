@@ -0,0 +1,46 @@
+package replay
+
+import (
+	"github.com/sjiamnocna/gopucha/internal/gameplay"
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+// NewPlayer creates the recorded game from mapsList, which should come from
+// Replay.LoadMaps.
+func NewPlayer(r *Replay, mapsList []maps.Map) *Player {
+	game := gameplay.NewGameWithSeed(mapsList, r.DisableMonsters, r.Seed)
+	if game == nil {
+		return nil
+	}
+	game.Endless = r.Endless
+	if r.BustPauseTicks > 0 {
+		game.BustPauseTicks = r.BustPauseTicks
+	}
+	return &Player{replay: r, game: game}
+}
+
+func (p *Player) Game() *gameplay.Game {
+	return p.game
+}
+
+// ApplyInputs replays every input recorded for the game's current tick.
+// Front-ends that run their own loop call it right before Game.Update.
+func (p *Player) ApplyInputs() {
+	for p.next < len(p.replay.Inputs) && p.replay.Inputs[p.next].Tick <= p.game.Tick {
+		p.game.Player.SetDirection(p.replay.Inputs[p.next].Direction)
+		p.next++
+	}
+}
+
+// Step advances the game by one tick, including moving on to the next level.
+func (p *Player) Step() {
+	p.ApplyInputs()
+	p.game.Update()
+	if p.game.LevelCompleted {
+		p.game.AdvanceLevel()
+	}
+}
+
+func (p *Player) Done() bool {
+	return p.game.GameOver || p.game.Won
+}
@@ -0,0 +1,187 @@
+package replay
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sjiamnocna/gopucha/internal/actors"
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+// New starts an empty recording for a game created with the given seed.
+func New(mapFile string, seed int64, tickInterval time.Duration, bustPauseTicks int, disableMonsters bool) (*Replay, error) {
+	hash, err := maps.HashFile(mapFile)
+	if err != nil {
+		return nil, err
+	}
+	return &Replay{
+		Version:         formatVersion,
+		Seed:            seed,
+		MapFile:         mapFile,
+		MapHash:         hash,
+		TickInterval:    tickInterval,
+		BustPauseTicks:  bustPauseTicks,
+		DisableMonsters: disableMonsters,
+	}, nil
+}
+
+// Record appends a direction change made before tick's Update.
+func (r *Replay) Record(tick int, d actors.Direction) {
+	r.Inputs = append(r.Inputs, Input{Tick: tick, Direction: d})
+}
+
+// LoadMaps loads the recorded map file, or mapFile when it is not empty,
+// and refuses it if the contents differ from the recording.
+func (r *Replay) LoadMaps(mapFile string) ([]maps.Map, error) {
+	if mapFile == "" {
+		mapFile = r.MapFile
+	}
+	hash, err := maps.HashFile(mapFile)
+	if err != nil {
+		return nil, err
+	}
+	if hash != r.MapHash {
+		return nil, fmt.Errorf("map file %s does not match the replay (hash %.12s, want %.12s)", mapFile, hash, r.MapHash)
+	}
+	return maps.LoadMapsFromFile(mapFile)
+}
+
+func Load(filename string) (*Replay, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r, err := Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return r, nil
+}
+
+func (r *Replay) Save(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := r.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (r *Replay) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%s %d\n", headerMagic, formatVersion)
+	fmt.Fprintf(bw, "seed: %d\n", r.Seed)
+	fmt.Fprintf(bw, "map: %s\n", r.MapFile)
+	fmt.Fprintf(bw, "mapHash: %s\n", r.MapHash)
+	fmt.Fprintf(bw, "tickInterval: %s\n", r.TickInterval)
+	fmt.Fprintf(bw, "bustPauseTicks: %d\n", r.BustPauseTicks)
+	fmt.Fprintf(bw, "noMonsters: %t\n", r.DisableMonsters)
+	fmt.Fprintf(bw, "endless: %t\n", r.Endless)
+	fmt.Fprintln(bw, inputsMarker)
+	for _, in := range r.Inputs {
+		fmt.Fprintf(bw, "%d %s\n", in.Tick, in.Direction)
+	}
+	return bw.Flush()
+}
+
+func Read(rd io.Reader) (*Replay, error) {
+	scanner := bufio.NewScanner(rd)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("empty replay")
+	}
+
+	header := strings.Fields(scanner.Text())
+	if len(header) != 2 || header[0] != headerMagic {
+		return nil, fmt.Errorf("not a replay file")
+	}
+	version, err := strconv.Atoi(header[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid replay version %q", header[1])
+	}
+	if version > formatVersion {
+		return nil, fmt.Errorf("replay version %d is newer than supported version %d", version, formatVersion)
+	}
+
+	r := &Replay{Version: version}
+	lineNo := 1
+	inInputs := false
+	lastTick := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if inInputs {
+			fields := strings.Fields(line)
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("line %d: expected \"tick direction\"", lineNo)
+			}
+			tick, err := strconv.Atoi(fields[0])
+			if err != nil || tick < lastTick {
+				return nil, fmt.Errorf("line %d: invalid tick %q", lineNo, fields[0])
+			}
+			d, err := actors.ParseDirection(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNo, err)
+			}
+			r.Inputs = append(r.Inputs, Input{Tick: tick, Direction: d})
+			lastTick = tick
+			continue
+		}
+
+		if line == inputsMarker {
+			inInputs = true
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", lineNo)
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "seed":
+			r.Seed, err = strconv.ParseInt(value, 10, 64)
+		case "map":
+			r.MapFile = value
+		case "maphash":
+			r.MapHash = value
+		case "tickinterval":
+			r.TickInterval, err = time.ParseDuration(value)
+		case "bustpauseticks":
+			r.BustPauseTicks, err = strconv.Atoi(value)
+		case "nomonsters":
+			r.DisableMonsters, err = strconv.ParseBool(value)
+		case "endless":
+			r.Endless, err = strconv.ParseBool(value)
+		default:
+			// Unknown keys from newer writers are ignored.
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid %s: %q", lineNo, strings.TrimSpace(key), value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if r.MapHash == "" {
+		return nil, fmt.Errorf("replay has no mapHash")
+	}
+	return r, nil
+}
@@ -0,0 +1,114 @@
+package replay
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sjiamnocna/gopucha/internal/actors"
+	"github.com/sjiamnocna/gopucha/internal/gameplay"
+)
+
+const testMap = `monsters: 2
+OOOOOOOOOO
+O--------O
+O-OO-OOO-O
+O--------O
+O-OOO-OO-O
+O--------O
+OOOOOOOOOO
+`
+
+func writeTestMap(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test_map.txt")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write map: %v", err)
+	}
+	return path
+}
+
+func TestReplayWriteRead(t *testing.T) {
+	mapFile := writeTestMap(t, testMap)
+	r, err := New(mapFile, -99, 150*time.Millisecond, 7, true)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	r.Endless = true
+	r.Record(0, actors.Left)
+	r.Record(12, actors.Down)
+
+	var buf bytes.Buffer
+	if err := r.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	if got.Seed != r.Seed || got.MapHash != r.MapHash || got.MapFile != r.MapFile ||
+		got.TickInterval != r.TickInterval || got.BustPauseTicks != r.BustPauseTicks ||
+		got.DisableMonsters != r.DisableMonsters || got.Endless != r.Endless {
+		t.Errorf("header mismatch: got %+v, want %+v", got, r)
+	}
+	if len(got.Inputs) != 2 || got.Inputs[1] != (Input{Tick: 12, Direction: actors.Down}) {
+		t.Errorf("inputs = %+v", got.Inputs)
+	}
+}
+
+func TestReplayRejectsChangedMap(t *testing.T) {
+	mapFile := writeTestMap(t, testMap)
+	r, err := New(mapFile, 1, 150*time.Millisecond, 7, false)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := os.WriteFile(mapFile, []byte(testMap+"\n---\n"+testMap), 0o644); err != nil {
+		t.Fatalf("Failed to rewrite map: %v", err)
+	}
+	if _, err := r.LoadMaps(""); err == nil {
+		t.Error("expected an error for a modified map file")
+	}
+}
+
+func TestPlaybackMatchesRecordedGame(t *testing.T) {
+	mapFile := writeTestMap(t, testMap)
+	r, err := New(mapFile, 2024, 150*time.Millisecond, 3, false)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	mapsList, err := r.LoadMaps("")
+	if err != nil {
+		t.Fatalf("LoadMaps() error = %v", err)
+	}
+
+	// Play a scripted game while recording it.
+	script := map[int]actors.Direction{0: actors.Down, 4: actors.Right, 11: actors.Up, 17: actors.Left, 30: actors.Down}
+	live := gameplay.NewGameWithSeed(mapsList, r.DisableMonsters, r.Seed)
+	live.BustPauseTicks = r.BustPauseTicks
+	for i := 0; i < 80 && !live.GameOver && !live.Won; i++ {
+		if d, ok := script[live.Tick]; ok {
+			live.Player.SetDirection(d)
+			r.Record(live.Tick, d)
+		}
+		live.Update()
+		if live.LevelCompleted {
+			live.AdvanceLevel()
+		}
+	}
+
+	p := NewPlayer(r, mapsList)
+	for p.Game().Tick < live.Tick && !p.Done() {
+		p.Step()
+	}
+	got := p.Game()
+	if got.Tick != live.Tick || got.Score != live.Score || got.Lives != live.Lives {
+		t.Fatalf("playback diverged: tick %d/%d score %d/%d lives %d/%d",
+			got.Tick, live.Tick, got.Score, live.Score, got.Lives, live.Lives)
+	}
+	if got.Player.X != live.Player.X || got.Player.Y != live.Player.Y {
+		t.Errorf("player at (%d,%d), want (%d,%d)", got.Player.X, got.Player.Y, live.Player.X, live.Player.Y)
+	}
+}
@@ -0,0 +1,34 @@
+package replay
+
+import (
+	"time"
+
+	"github.com/sjiamnocna/gopucha/internal/actors"
+	"github.com/sjiamnocna/gopucha/internal/gameplay"
+)
+
+// Replay holds everything needed to re-drive a game tick for tick.
+type Replay struct {
+	Version         int
+	Seed            int64
+	MapFile         string
+	MapHash         string
+	TickInterval    time.Duration
+	BustPauseTicks  int
+	DisableMonsters bool
+	Endless         bool
+	Inputs          []Input
+}
+
+// Input is a Player.SetDirection call made before the given tick's Update.
+type Input struct {
+	Tick      int
+	Direction actors.Direction
+}
+
+// Player feeds a recorded input stream back into a fresh game.
+type Player struct {
+	replay *Replay
+	game   *gameplay.Game
+	next   int
+}
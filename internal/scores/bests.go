@@ -0,0 +1,255 @@
+package scores
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+// OpenBests loads the personal bests for mapFile from dir. A map without
+// saved times gets empty bests that Save will create.
+func OpenBests(dir, mapFile string) (*Bests, error) {
+	hash, err := maps.HashFile(mapFile)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, hash+bestsSuffix)
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return &Bests{MapFile: mapFile, MapHash: hash, path: path}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	b, err := ReadBests(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if b.MapHash != hash {
+		return nil, fmt.Errorf("%s: stored for map hash %.12s, want %.12s", path, b.MapHash, hash)
+	}
+	b.MapFile = mapFile
+	b.path = path
+	return b, nil
+}
+
+// Record keeps every split of run that beats the stored one, and the total
+// when run won faster than before. It reports whether anything improved.
+func (b *Bests) Record(run Run) bool {
+	improved := false
+	for i, d := range run.Splits {
+		if d <= 0 {
+			continue
+		}
+		for len(b.Splits) <= i {
+			b.Splits = append(b.Splits, 0)
+		}
+		if b.Splits[i] == 0 || d < b.Splits[i] {
+			b.Splits[i] = d
+			improved = true
+		}
+	}
+	if total := run.Total(); run.Won && (b.Total == 0 || total < b.Total) {
+		b.Total = total
+		improved = true
+	}
+	return improved
+}
+
+// Total is the time of all cleared levels together.
+func (r Run) Total() time.Duration {
+	var total time.Duration
+	for _, d := range r.Splits {
+		total += d
+	}
+	return total
+}
+
+// WriteComparison prints run level by level next to the par times and
+// the bests it is compared against. Call it before Record.
+func (b *Bests) WriteComparison(w io.Writer, run Run) {
+	fmt.Fprintf(w, "%-3s %-16s %8s %8s %8s %8s\n", "#", "Level", "Time", "Par", "Best", "+/-")
+	for i, d := range run.Splits {
+		name := fmt.Sprintf("Level %d", i+1)
+		if i < len(run.Levels) && strings.TrimSpace(run.Levels[i]) != "" {
+			name = strings.TrimSpace(run.Levels[i])
+		}
+		if runes := []rune(name); len(runes) > 16 {
+			name = string(runes[:16])
+		}
+		var par, best time.Duration
+		if i < len(run.Pars) {
+			par = run.Pars[i]
+		}
+		if i < len(b.Splits) {
+			best = b.Splits[i]
+		}
+		fmt.Fprintf(w, "%-3d %-16s %8s %8s %8s %8s\n", i+1, name, FormatClock(d), FormatClock(par), FormatClock(best), formatDelta(d, best))
+	}
+
+	result := "Total"
+	if !run.Won {
+		result = "Total (not won)"
+	}
+	best := time.Duration(0)
+	if run.Won {
+		best = b.Total
+	}
+	fmt.Fprintf(w, "%-20s %8s %8s %8s %8s\n", result, FormatClock(run.Total()), FormatClock(sumPars(run)), FormatClock(best), formatDelta(run.Total(), best))
+}
+
+// sumPars is the par of the whole pack, 0 unless every level has one.
+func sumPars(run Run) time.Duration {
+	if len(run.Pars) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, par := range run.Pars {
+		if par <= 0 {
+			return 0
+		}
+		total += par
+	}
+	return total
+}
+
+// FormatClock shows d as m:ss.s, or "-" for no time.
+func FormatClock(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	tenths := d.Round(100*time.Millisecond) / (100 * time.Millisecond)
+	return fmt.Sprintf("%d:%02d.%d", tenths/600, tenths/10%60, tenths%10)
+}
+
+// formatDelta shows how far d is ahead of (-) or behind (+) best.
+func formatDelta(d, best time.Duration) string {
+	if d <= 0 || best <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%+.1f", (d - best).Seconds())
+}
+
+// Save writes the bests back to the file OpenBests read them from.
+func (b *Bests) Save() error {
+	if b.path == "" {
+		return fmt.Errorf("bests were not opened from a directory")
+	}
+	if err := os.MkdirAll(filepath.Dir(b.path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.Create(b.path)
+	if err != nil {
+		return err
+	}
+	if err := b.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (b *Bests) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%s %d\n", bestsMagic, bestsVersion)
+	fmt.Fprintf(bw, "map: %s\n", b.MapFile)
+	fmt.Fprintf(bw, "mapHash: %s\n", b.MapHash)
+	if b.Total > 0 {
+		fmt.Fprintf(bw, "total: %s\n", b.Total)
+	}
+	fmt.Fprintln(bw, entriesMarker)
+	for i, d := range b.Splits {
+		if d > 0 {
+			fmt.Fprintf(bw, "%d %s\n", i+1, d)
+		}
+	}
+	return bw.Flush()
+}
+
+func ReadBests(rd io.Reader) (*Bests, error) {
+	scanner := bufio.NewScanner(rd)
+	if err := readHeader(scanner, bestsMagic, bestsVersion, "bests file"); err != nil {
+		return nil, err
+	}
+
+	b := &Bests{}
+	lineNo := 1
+	inSplits := false
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if inSplits {
+			level, d, err := parseSplit(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNo, err)
+			}
+			for len(b.Splits) < level {
+				b.Splits = append(b.Splits, 0)
+			}
+			b.Splits[level-1] = d
+			continue
+		}
+
+		if line == entriesMarker {
+			inSplits = true
+			continue
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", lineNo)
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "map":
+			b.MapFile = value
+		case "maphash":
+			b.MapHash = value
+		case "total":
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("line %d: invalid total %q", lineNo, value)
+			}
+			b.Total = d
+		default:
+			// Unknown keys from newer writers are ignored.
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if b.MapHash == "" {
+		return nil, fmt.Errorf("bests file has no mapHash")
+	}
+	return b, nil
+}
+
+func parseSplit(line string) (int, time.Duration, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("expected \"level time\"")
+	}
+	level, err := strconv.Atoi(fields[0])
+	if err != nil || level < 1 {
+		return 0, 0, fmt.Errorf("invalid level %q", fields[0])
+	}
+	d, err := time.ParseDuration(fields[1])
+	if err != nil || d <= 0 {
+		return 0, 0, fmt.Errorf("invalid time %q", fields[1])
+	}
+	return level, d, nil
+}
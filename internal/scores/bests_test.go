@@ -0,0 +1,102 @@
+package scores
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBestsRecordSaveOpen(t *testing.T) {
+	mapFile := writeTestMap(t, testMap+"\n---\n"+testMap)
+	dir := t.TempDir()
+
+	bests, err := OpenBests(dir, mapFile)
+	if err != nil {
+		t.Fatalf("OpenBests() error = %v", err)
+	}
+	lost := Run{Splits: []time.Duration{12 * time.Second}}
+	if !bests.Record(lost) {
+		t.Errorf("first split was not an improvement")
+	}
+	if bests.Total != 0 {
+		t.Errorf("lost run set total %v", bests.Total)
+	}
+
+	won := Run{Splits: []time.Duration{14 * time.Second, 9 * time.Second}, Won: true}
+	if !bests.Record(won) {
+		t.Errorf("won run was not an improvement")
+	}
+	if want := []time.Duration{12 * time.Second, 9 * time.Second}; !reflect.DeepEqual(bests.Splits, want) {
+		t.Errorf("Splits = %v, want %v", bests.Splits, want)
+	}
+	if bests.Total != 23*time.Second {
+		t.Errorf("Total = %v, want 23s", bests.Total)
+	}
+	if bests.Record(won) {
+		t.Errorf("same run again counted as an improvement")
+	}
+	if err := bests.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := OpenBests(dir, mapFile)
+	if err != nil {
+		t.Fatalf("OpenBests() error = %v", err)
+	}
+	if loaded.Total != bests.Total || !reflect.DeepEqual(loaded.Splits, bests.Splits) {
+		t.Errorf("loaded %v %v, want %v %v", loaded.Total, loaded.Splits, bests.Total, bests.Splits)
+	}
+
+	// The high-score table of the same map is a different file.
+	table, err := Open(dir, mapFile)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if len(table.Entries) != 0 {
+		t.Errorf("bests leaked into the score table: %+v", table.Entries)
+	}
+}
+
+func TestBestsWriteComparison(t *testing.T) {
+	bests := &Bests{Splits: []time.Duration{10 * time.Second}}
+	run := Run{
+		Levels: []string{"Intro", ""},
+		Pars:   []time.Duration{15 * time.Second, 20 * time.Second},
+		Splits: []time.Duration{10500 * time.Millisecond, 62 * time.Second},
+		Won:    true,
+	}
+
+	var out bytes.Buffer
+	bests.WriteComparison(&out, run)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want 4:\n%s", len(lines), out.String())
+	}
+	for i, want := range [][]string{
+		{"Intro", "0:10.5", "0:15.0", "0:10.0", "+0.5"},
+		{"Level 2", "1:02.0", "0:20.0", "-"},
+		{"Total", "1:12.5", "0:35.0"},
+	} {
+		for _, field := range want {
+			if !strings.Contains(lines[i+1], field) {
+				t.Errorf("line %q lacks %q", lines[i+1], field)
+			}
+		}
+	}
+}
+
+func TestFormatClock(t *testing.T) {
+	cases := map[time.Duration]string{
+		0:                       "-",
+		1449 * time.Millisecond: "0:01.4",
+		61 * time.Second:        "1:01.0",
+		10 * time.Minute:        "10:00.0",
+	}
+	for d, want := range cases {
+		if got := FormatClock(d); got != want {
+			t.Errorf("FormatClock(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package scores
+
+const (
+	formatVersion = 1
+	headerMagic   = "gopucha-scores"
+	entriesMarker = "---"
+	dateLayout    = "2006-01-02"
+
+	bestsVersion = 1
+	bestsMagic   = "gopucha-bests"
+	bestsSuffix  = ".bests.txt"
+
+	maxNameLength = 20
+	defaultName   = "Player"
+
+	// MaxEntries is how many scores a table keeps per map file.
+	MaxEntries = 10
+)
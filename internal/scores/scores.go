@@ -0,0 +1,243 @@
+package scores
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+// DefaultDir is where tables live unless a caller picks another directory.
+func DefaultDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gopucha", "scores"), nil
+}
+
+// Open loads the table for mapFile from dir. A map without saved scores
+// gets an empty table that Save will create.
+func Open(dir, mapFile string) (*Table, error) {
+	hash, err := maps.HashFile(mapFile)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, hash+".txt")
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return &Table{MapFile: mapFile, MapHash: hash, path: path}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	t, err := Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if t.MapHash != hash {
+		return nil, fmt.Errorf("%s: stored for map hash %.12s, want %.12s", path, t.MapHash, hash)
+	}
+	t.MapFile = mapFile
+	t.path = path
+	return t, nil
+}
+
+// Qualifies reports whether score would make it onto the table.
+func (t *Table) Qualifies(score int) bool {
+	if score <= 0 {
+		return false
+	}
+	return len(t.Entries) < MaxEntries || score > t.Entries[len(t.Entries)-1].Score
+}
+
+// Add inserts e behind any equal scores and returns its 0-based rank, or -1
+// if it did not make the table.
+func (t *Table) Add(e Entry) int {
+	if !t.Qualifies(e.Score) {
+		return -1
+	}
+	rank := sort.Search(len(t.Entries), func(i int) bool {
+		return t.Entries[i].Score < e.Score
+	})
+	t.Entries = append(t.Entries, Entry{})
+	copy(t.Entries[rank+1:], t.Entries[rank:])
+	t.Entries[rank] = e
+	if len(t.Entries) > MaxEntries {
+		t.Entries = t.Entries[:MaxEntries]
+	}
+	return rank
+}
+
+// Save writes the table back to the file Open read it from.
+func (t *Table) Save() error {
+	if t.path == "" {
+		return fmt.Errorf("table was not opened from a directory")
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.Create(t.path)
+	if err != nil {
+		return err
+	}
+	if err := t.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// WriteReport prints the table for people rather than for Read.
+func (t *Table) WriteReport(w io.Writer) {
+	fmt.Fprintf(w, "High scores for %s\n", t.MapFile)
+	if len(t.Entries) == 0 {
+		fmt.Fprintln(w, "  (none yet)")
+		return
+	}
+	for i, e := range t.Entries {
+		result := fmt.Sprintf("level %d", e.Level)
+		if e.Won {
+			result = "won"
+		}
+		fmt.Fprintf(w, "%3d. %-*s %8d  %-9s %s\n", i+1, maxNameLength, e.Name, e.Score, result, e.Date.Format(dateLayout))
+	}
+}
+
+// CleanName turns typed input into a name that fits the table format.
+func CleanName(name string) string {
+	name = strings.Join(strings.Fields(name), " ")
+	if runes := []rune(name); len(runes) > maxNameLength {
+		name = strings.TrimSpace(string(runes[:maxNameLength]))
+	}
+	if name == "" {
+		return defaultName
+	}
+	return name
+}
+
+func (t *Table) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%s %d\n", headerMagic, formatVersion)
+	fmt.Fprintf(bw, "map: %s\n", t.MapFile)
+	fmt.Fprintf(bw, "mapHash: %s\n", t.MapHash)
+	fmt.Fprintln(bw, entriesMarker)
+	for _, e := range t.Entries {
+		// The name goes last so it may contain spaces.
+		fmt.Fprintf(bw, "%d %d %t %s %s\n", e.Score, e.Level, e.Won, e.Date.Format(dateLayout), e.Name)
+	}
+	return bw.Flush()
+}
+
+func Read(rd io.Reader) (*Table, error) {
+	scanner := bufio.NewScanner(rd)
+	if err := readHeader(scanner, headerMagic, formatVersion, "score table"); err != nil {
+		return nil, err
+	}
+
+	t := &Table{}
+	lineNo := 1
+	inEntries := false
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if inEntries {
+			e, err := parseEntry(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNo, err)
+			}
+			t.Entries = append(t.Entries, e)
+			continue
+		}
+
+		if line == entriesMarker {
+			inEntries = true
+			continue
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", lineNo)
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "map":
+			t.MapFile = value
+		case "maphash":
+			t.MapHash = value
+		default:
+			// Unknown keys from newer writers are ignored.
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if t.MapHash == "" {
+		return nil, fmt.Errorf("score table has no mapHash")
+	}
+	sort.SliceStable(t.Entries, func(i, j int) bool {
+		return t.Entries[i].Score > t.Entries[j].Score
+	})
+	return t, nil
+}
+
+// readHeader checks the "magic version" first line shared by the files of
+// this package.
+func readHeader(scanner *bufio.Scanner, magic string, maxVersion int, what string) error {
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		return fmt.Errorf("empty %s", what)
+	}
+
+	header := strings.Fields(scanner.Text())
+	if len(header) != 2 || header[0] != magic {
+		return fmt.Errorf("not a %s", what)
+	}
+	version, err := strconv.Atoi(header[1])
+	if err != nil {
+		return fmt.Errorf("invalid %s version %q", what, header[1])
+	}
+	if version > maxVersion {
+		return fmt.Errorf("%s version %d is newer than supported version %d", what, version, maxVersion)
+	}
+	return nil
+}
+
+func parseEntry(line string) (Entry, error) {
+	fields := strings.SplitN(line, " ", 5)
+	if len(fields) != 5 {
+		return Entry{}, fmt.Errorf("expected \"score level won date name\"")
+	}
+	var e Entry
+	var err error
+	if e.Score, err = strconv.Atoi(fields[0]); err != nil {
+		return Entry{}, fmt.Errorf("invalid score %q", fields[0])
+	}
+	if e.Level, err = strconv.Atoi(fields[1]); err != nil {
+		return Entry{}, fmt.Errorf("invalid level %q", fields[1])
+	}
+	if e.Won, err = strconv.ParseBool(fields[2]); err != nil {
+		return Entry{}, fmt.Errorf("invalid won flag %q", fields[2])
+	}
+	if e.Date, err = time.Parse(dateLayout, fields[3]); err != nil {
+		return Entry{}, fmt.Errorf("invalid date %q", fields[3])
+	}
+	e.Name = fields[4]
+	return e, nil
+}
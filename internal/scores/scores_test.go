@@ -0,0 +1,109 @@
+package scores
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testMap = `OOOOO
+O---O
+OOOOO
+`
+
+func writeTestMap(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test_map.txt")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write map: %v", err)
+	}
+	return path
+}
+
+func TestTableAddKeepsBestScores(t *testing.T) {
+	table := &Table{}
+	for i := 1; i <= MaxEntries; i++ {
+		table.Add(Entry{Name: "p", Score: i * 100})
+	}
+	if table.Qualifies(100) {
+		t.Errorf("score equal to the lowest entry should not qualify on a full table")
+	}
+	if rank := table.Add(Entry{Name: "new", Score: 550}); rank != 5 {
+		t.Errorf("rank = %d, want 5", rank)
+	}
+	if len(table.Entries) != MaxEntries {
+		t.Fatalf("table has %d entries, want %d", len(table.Entries), MaxEntries)
+	}
+	if table.Entries[0].Score != 1000 || table.Entries[MaxEntries-1].Score != 200 {
+		t.Errorf("table not sorted best first: %+v", table.Entries)
+	}
+	if rank := table.Add(Entry{Name: "zero", Score: 0}); rank != -1 {
+		t.Errorf("zero score added at rank %d", rank)
+	}
+}
+
+func TestTableSaveOpen(t *testing.T) {
+	mapFile := writeTestMap(t, testMap)
+	dir := t.TempDir()
+
+	table, err := Open(dir, mapFile)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if len(table.Entries) != 0 {
+		t.Fatalf("new table has entries: %+v", table.Entries)
+	}
+	date := time.Date(2024, 5, 17, 0, 0, 0, 0, time.UTC)
+	table.Add(Entry{Name: "Ada Lovelace", Score: 1230, Level: 3, Date: date})
+	table.Add(Entry{Name: "bob", Score: 4560, Level: 8, Won: true, Date: date})
+	if err := table.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Open(dir, mapFile)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Entries, table.Entries) {
+		t.Errorf("loaded %+v, want %+v", loaded.Entries, table.Entries)
+	}
+
+	// Another map file gets its own table.
+	other, err := Open(dir, writeTestMap(t, testMap+"\n---\n"+testMap))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if len(other.Entries) != 0 {
+		t.Errorf("edited map file shares the old table: %+v", other.Entries)
+	}
+}
+
+func TestReadRejectsBadEntries(t *testing.T) {
+	cases := map[string]string{
+		"not a table": "hello\n",
+		"no hash":     "gopucha-scores 1\n---\n",
+		"bad score":   "gopucha-scores 1\nmapHash: ab\n---\nlots 1 false 2024-01-01 bob\n",
+		"no name":     "gopucha-scores 1\nmapHash: ab\n---\n10 1 false 2024-01-01\n",
+	}
+	for name, content := range cases {
+		if _, err := Read(bytes.NewBufferString(content)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestCleanName(t *testing.T) {
+	cases := map[string]string{
+		"  Ada \n Lovelace ":            "Ada Lovelace",
+		"":                              "Player",
+		"abcdefghijklmnopqrstuvwxyz012": "abcdefghijklmnopqrst",
+	}
+	for in, want := range cases {
+		if got := CleanName(in); got != want {
+			t.Errorf("CleanName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package scores
+
+import "time"
+
+// Table is the high-score list of one map file. Tables are stored by the
+// map's content hash, so editing a map pack starts a fresh table.
+type Table struct {
+	MapFile string
+	MapHash string
+	Entries []Entry // Best first
+	path    string
+}
+
+// Entry is one finished game.
+type Entry struct {
+	Name  string
+	Score int
+	Level int // Level the game ended on, 1-based
+	Won   bool
+	Date  time.Time
+}
+
+// Bests are the personal best time-attack times of one map file, stored
+// next to its high-score table.
+type Bests struct {
+	MapFile string
+	MapHash string
+	Total   time.Duration   // Best time for the whole pack, 0 until it is won
+	Splits  []time.Duration // Best time per level, 0 for levels never cleared
+	path    string
+}
+
+// Run is one finished time-attack game.
+type Run struct {
+	Levels []string        // Level names of the whole pack
+	Pars   []time.Duration // Par time per level, 0 where a level has none
+	Splits []time.Duration // Time of each cleared level, in order
+	Won    bool
+}
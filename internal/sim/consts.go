@@ -0,0 +1,11 @@
+package sim
+
+const (
+	DefaultRuns     = 1000
+	DefaultMaxTicks = 5000
+
+	// Shades for visited heatmap cells, from rare to frequent.
+	heatShades = "123456789"
+	// How many last-dot cells the report lists.
+	lastDotTop = 5
+)
@@ -0,0 +1,226 @@
+package sim
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/sjiamnocna/gopucha/internal/gameplay"
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+// RunLevel plays cfg.Runs independent games of a single level.
+func RunLevel(level int, m maps.Map, cfg Config) LevelStats {
+	cfg = withDefaults(cfg)
+	stats := LevelStats{
+		Level:     level,
+		Name:      m.Name,
+		Runs:      cfg.Runs,
+		DeathHeat: newGrid(m.Width, m.Height),
+		LastDots:  newGrid(m.Width, m.Height),
+	}
+
+	jobs := make(chan int)
+	results := make(chan runResult)
+	var wg sync.WaitGroup
+	for w := 0; w < cfg.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results <- runOnce(m, cfg, cfg.Seed+int64(i))
+			}
+		}()
+	}
+	go func() {
+		for i := 0; i < cfg.Runs; i++ {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	for r := range results {
+		stats.add(r)
+	}
+	return stats
+}
+
+// RunAll simulates every level of a map pack in order.
+func RunAll(mapsList []maps.Map, cfg Config) []LevelStats {
+	all := make([]LevelStats, 0, len(mapsList))
+	for i, m := range mapsList {
+		all = append(all, RunLevel(i, m, cfg))
+	}
+	return all
+}
+
+func withDefaults(cfg Config) Config {
+	if cfg.Runs <= 0 {
+		cfg.Runs = DefaultRuns
+	}
+	if cfg.MaxTicks <= 0 {
+		cfg.MaxTicks = DefaultMaxTicks
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.NumCPU()
+	}
+	if cfg.NewController == nil {
+		cfg.NewController = NewRandomWalker
+	}
+	return cfg
+}
+
+func runOnce(m maps.Map, cfg Config, seed int64) runResult {
+	game := gameplay.NewGameWithSeed([]maps.Map{m}, cfg.DisableMonsters, seed)
+	ctrl := cfg.NewController(seed)
+
+	var r runResult
+	for game.Tick < cfg.MaxTicks {
+		ctrl.Steer(game)
+		game.Update()
+
+		if game.LifeLost || game.GameOver {
+			r.deaths = append(r.deaths, [2]int{game.Player.X, game.Player.Y})
+		}
+		if game.GameOver {
+			r.gameOver = true
+			break
+		}
+		if game.LevelCompleted {
+			r.cleared = true
+			r.lastDot = [2]int{game.Player.X, game.Player.Y}
+			break
+		}
+	}
+	r.ticks = game.Tick
+	return r
+}
+
+func (s *LevelStats) add(r runResult) {
+	for _, d := range r.deaths {
+		s.Deaths++
+		s.DeathHeat[d[1]][d[0]]++
+	}
+	switch {
+	case r.cleared:
+		s.Cleared++
+		s.ClearTicks += r.ticks
+		s.LastDots[r.lastDot[1]][r.lastDot[0]]++
+	case r.gameOver:
+		s.GameOvers++
+	default:
+		s.Timeouts++
+	}
+}
+
+// AvgClearTicks is the mean ticks of the cleared runs, 0 if none cleared.
+func (s *LevelStats) AvgClearTicks() float64 {
+	if s.Cleared == 0 {
+		return 0
+	}
+	return float64(s.ClearTicks) / float64(s.Cleared)
+}
+
+// DeathRate is the share of runs that ended in game over.
+func (s *LevelStats) DeathRate() float64 {
+	if s.Runs == 0 {
+		return 0
+	}
+	return float64(s.GameOvers) / float64(s.Runs)
+}
+
+// WriteReport prints the statistics with the death heatmap and the most
+// frequent last dots drawn over the level's walls.
+func (s *LevelStats) WriteReport(w io.Writer, m *maps.Map) {
+	name := s.Name
+	if name == "" {
+		name = fmt.Sprintf("Level %d", s.Level+1)
+	}
+	fmt.Fprintf(w, "== %d. %s ==\n", s.Level+1, name)
+	fmt.Fprintf(w, "runs: %d  cleared: %d (%.1f%%)  game over: %d (%.1f%%)  timeouts: %d\n",
+		s.Runs, s.Cleared, percent(s.Cleared, s.Runs), s.GameOvers, 100*s.DeathRate(), s.Timeouts)
+	fmt.Fprintf(w, "avg ticks to clear: %.1f  deaths per run: %.2f\n", s.AvgClearTicks(), float64(s.Deaths)/float64(max(s.Runs, 1)))
+
+	if s.Deaths > 0 {
+		fmt.Fprintln(w, "death heatmap:")
+		writeHeatmap(w, m, s.DeathHeat)
+	}
+
+	if top := topCells(s.LastDots, lastDotTop); len(top) > 0 {
+		fmt.Fprintln(w, "most frequent last dots:")
+		for _, c := range top {
+			fmt.Fprintf(w, "  row %d, col %d: %d (%.1f%% of clears)\n", c.y, c.x, c.count, percent(c.count, s.Cleared))
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+func writeHeatmap(w io.Writer, m *maps.Map, heat [][]int) {
+	peak := 0
+	for _, row := range heat {
+		for _, v := range row {
+			peak = max(peak, v)
+		}
+	}
+	for y := 0; y < m.Height; y++ {
+		line := make([]byte, m.Width)
+		for x := 0; x < m.Width; x++ {
+			switch {
+			case m.IsWall(x, y):
+				line[x] = 'O'
+			case heat[y][x] == 0:
+				line[x] = ' '
+			default:
+				line[x] = heatShades[(heat[y][x]*len(heatShades)-1)/peak]
+			}
+		}
+		fmt.Fprintf(w, "  %s\n", line)
+	}
+}
+
+type cellCount struct {
+	x, y, count int
+}
+
+func topCells(grid [][]int, n int) []cellCount {
+	var cells []cellCount
+	for y, row := range grid {
+		for x, v := range row {
+			if v > 0 {
+				cells = append(cells, cellCount{x: x, y: y, count: v})
+			}
+		}
+	}
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i].count != cells[j].count {
+			return cells[i].count > cells[j].count
+		}
+		if cells[i].y != cells[j].y {
+			return cells[i].y < cells[j].y
+		}
+		return cells[i].x < cells[j].x
+	})
+	if len(cells) > n {
+		cells = cells[:n]
+	}
+	return cells
+}
+
+func newGrid(width, height int) [][]int {
+	grid := make([][]int, height)
+	for y := range grid {
+		grid[y] = make([]int, width)
+	}
+	return grid
+}
+
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(part) / float64(total)
+}
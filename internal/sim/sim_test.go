@@ -0,0 +1,76 @@
+package sim
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+func loopMap() maps.Map {
+	rows := []string{
+		"OOOOOOO",
+		"O-----O",
+		"O-OOO-O",
+		"O-----O",
+		"OOOOOOO",
+	}
+	m := maps.Map{Width: len(rows[0]), Height: len(rows), MonsterCount: 1, SpeedModifier: 1}
+	for _, row := range rows {
+		cells := make([]maps.Cell, len(row))
+		for x, ch := range row {
+			switch ch {
+			case 'O':
+				cells[x] = maps.Wall
+			case '-':
+				cells[x] = maps.Dot
+			}
+		}
+		m.Cells = append(m.Cells, cells)
+	}
+	return m
+}
+
+func TestRunLevelWithoutMonstersClears(t *testing.T) {
+	stats := RunLevel(0, loopMap(), Config{Runs: 20, MaxTicks: 2000, Seed: 5, DisableMonsters: true})
+	if stats.Cleared != 20 {
+		t.Fatalf("cleared %d of 20 runs (timeouts %d)", stats.Cleared, stats.Timeouts)
+	}
+	if stats.Deaths != 0 || stats.GameOvers != 0 {
+		t.Errorf("deaths without monsters: %+v", stats)
+	}
+	if stats.AvgClearTicks() < 11 {
+		t.Errorf("avg ticks %.1f is below the dot count", stats.AvgClearTicks())
+	}
+
+	lastDots := 0
+	for _, row := range stats.LastDots {
+		for _, v := range row {
+			lastDots += v
+		}
+	}
+	if lastDots != stats.Cleared {
+		t.Errorf("last dots recorded %d times, want %d", lastDots, stats.Cleared)
+	}
+}
+
+func TestRunLevelIsReproducible(t *testing.T) {
+	cfg := Config{Runs: 30, MaxTicks: 500, Seed: 9, Workers: 4}
+	a := RunLevel(0, loopMap(), cfg)
+	b := RunLevel(0, loopMap(), cfg)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("same seeds gave different stats:\n%+v\n%+v", a, b)
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	m := loopMap()
+	stats := RunLevel(0, m, Config{Runs: 10, MaxTicks: 500, Seed: 1})
+	var buf bytes.Buffer
+	stats.WriteReport(&buf, &m)
+	if !strings.Contains(buf.String(), "runs: 10") {
+		t.Errorf("report is missing the run count:\n%s", buf.String())
+	}
+}
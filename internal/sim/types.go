@@ -0,0 +1,51 @@
+package sim
+
+import (
+	"math/rand/v2"
+
+	"github.com/sjiamnocna/gopucha/internal/gameplay"
+)
+
+// Controller plays the Pampuch figure in a headless game. Steer is called
+// before every Update and should use Player.SetDirection.
+type Controller interface {
+	Steer(g *gameplay.Game)
+}
+
+// Config describes a batch of runs per level.
+type Config struct {
+	Runs            int
+	MaxTicks        int   // Runs still going after this many ticks count as timeouts
+	Seed            int64 // Run i uses Seed+i for the game and its controller
+	DisableMonsters bool
+	Workers         int
+	NewController   func(seed int64) Controller
+}
+
+// LevelStats aggregates all runs of one level.
+type LevelStats struct {
+	Level      int
+	Name       string
+	Runs       int
+	Cleared    int
+	ClearTicks int // Sum over cleared runs
+	Deaths     int
+	GameOvers  int
+	Timeouts   int
+	DeathHeat  [][]int // Deaths per cell
+	LastDots   [][]int // How often each cell held the last dot
+}
+
+// RandomWalker keeps going straight and picks a random open direction at
+// junctions and dead ends.
+type RandomWalker struct {
+	rng *rand.Rand
+}
+
+type runResult struct {
+	cleared  bool
+	gameOver bool
+	ticks    int
+	deaths   [][2]int
+	lastDot  [2]int
+}
@@ -0,0 +1,37 @@
+package sim
+
+import (
+	"math/rand/v2"
+
+	"github.com/sjiamnocna/gopucha/internal/actors"
+	"github.com/sjiamnocna/gopucha/internal/gameplay"
+)
+
+func NewRandomWalker(seed int64) Controller {
+	return &RandomWalker{rng: rand.New(rand.NewPCG(uint64(seed), 0))}
+}
+
+func (w *RandomWalker) Steer(g *gameplay.Game) {
+	p := g.Player
+	var open []actors.Direction
+	for _, d := range []actors.Direction{actors.Up, actors.Down, actors.Left, actors.Right} {
+		if _, _, ok := actors.Step(g.CurrentMap, p.X, p.Y, d); ok {
+			open = append(open, d)
+		}
+	}
+	if len(open) == 0 {
+		return
+	}
+
+	ahead := false
+	for _, d := range open {
+		if d == p.Direction {
+			ahead = true
+		}
+	}
+	// Corridors are followed; anything else is a decision point.
+	if ahead && len(open) <= 2 {
+		return
+	}
+	p.SetDirection(open[w.rng.IntN(len(open))])
+}
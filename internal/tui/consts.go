@@ -0,0 +1,33 @@
+package tui
+
+import "time"
+
+const (
+	keyNone key = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyQuit
+)
+
+const (
+	phaseLevelStart phase = iota
+	phasePlaying
+	phaseLevelComplete
+	phaseOver
+)
+
+const (
+	defaultTickInterval = 150 * time.Millisecond
+	levelStartPause     = 2 * time.Second
+	levelCompletePause  = 1 * time.Second
+	bustPauseDuration   = 1 * time.Second
+
+	clearScreen = "\033[H\033[2J"
+	cursorHome  = "\033[H"
+	hideCursor  = "\033[?25l"
+	showCursor  = "\033[?25h"
+	clearLine   = "\033[K"
+	clearToEnd  = "\033[J"
+)
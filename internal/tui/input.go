@@ -0,0 +1,54 @@
+package tui
+
+import "io"
+
+// decodeKeys turns raw terminal bytes into game keys. Arrow keys arrive as
+// ESC [ A..D (or ESC O A..D in application cursor mode); WASD is lower case
+// only so a split escape sequence never reads as a letter.
+func decodeKeys(buf []byte) []key {
+	var keys []key
+	for i := 0; i < len(buf); i++ {
+		switch b := buf[i]; b {
+		case 0x1b:
+			if i+2 < len(buf) && (buf[i+1] == '[' || buf[i+1] == 'O') {
+				switch buf[i+2] {
+				case 'A':
+					keys = append(keys, keyUp)
+				case 'B':
+					keys = append(keys, keyDown)
+				case 'C':
+					keys = append(keys, keyRight)
+				case 'D':
+					keys = append(keys, keyLeft)
+				}
+				i += 2
+			}
+		case 'w':
+			keys = append(keys, keyUp)
+		case 's':
+			keys = append(keys, keyDown)
+		case 'a':
+			keys = append(keys, keyLeft)
+		case 'd':
+			keys = append(keys, keyRight)
+		case 'q', 'Q', 0x03: // 0x03 is Ctrl+C, which raw mode no longer turns into SIGINT
+			keys = append(keys, keyQuit)
+		}
+	}
+	return keys
+}
+
+// readKeys forwards decoded keys until the reader fails.
+func readKeys(r io.Reader, keys chan<- key) {
+	buf := make([]byte, 64)
+	for {
+		n, err := r.Read(buf)
+		for _, k := range decodeKeys(buf[:n]) {
+			keys <- k
+		}
+		if err != nil {
+			close(keys)
+			return
+		}
+	}
+}
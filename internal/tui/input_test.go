@@ -0,0 +1,28 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []key
+	}{
+		{name: "wasd", in: "wasd", want: []key{keyUp, keyLeft, keyDown, keyRight}},
+		{name: "arrows", in: "\x1b[A\x1b[B\x1b[D\x1b[C", want: []key{keyUp, keyDown, keyLeft, keyRight}},
+		{name: "application arrows", in: "\x1bOA", want: []key{keyUp}},
+		{name: "quit", in: "q\x03", want: []key{keyQuit, keyQuit}},
+		{name: "unknown bytes", in: "x\x1b[5~", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeKeys([]byte(tt.in)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeKeys(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/sjiamnocna/gopucha/internal/actors"
+	"github.com/sjiamnocna/gopucha/internal/gameplay"
+	"github.com/sjiamnocna/gopucha/internal/maps"
+	"github.com/sjiamnocna/gopucha/internal/replay"
+)
+
+// Run plays the game in the terminal until it ends or the player quits.
+func Run(opts Options) error {
+	s, err := newSession(opts)
+	if err != nil {
+		return err
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("terminal mode needs an interactive terminal")
+	}
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("failed to enter raw mode: %v", err)
+	}
+	defer term.Restore(fd, oldState)
+
+	out := bufio.NewWriter(os.Stdout)
+	s.out = out
+	fmt.Fprint(out, hideCursor+clearScreen)
+	defer func() {
+		fmt.Fprint(out, showCursor)
+		out.Flush()
+	}()
+	defer s.saveRecording()
+
+	keys := make(chan key, 16)
+	go readKeys(os.Stdin, keys)
+
+	ticker := time.NewTicker(s.tickInterval)
+	defer ticker.Stop()
+
+	s.render()
+	for {
+		select {
+		case k, ok := <-keys:
+			if !ok || k == keyQuit {
+				return nil
+			}
+			if s.phase == phaseOver {
+				return nil
+			}
+			s.handleKey(k)
+		case <-ticker.C:
+			if s.phase == phaseOver {
+				continue
+			}
+			s.step()
+			s.render()
+		}
+	}
+}
+
+func newSession(opts Options) (*session, error) {
+	s := &session{
+		tickInterval: opts.TickInterval,
+		recordFile:   opts.RecordFile,
+	}
+	if s.tickInterval <= 0 {
+		s.tickInterval = defaultTickInterval
+	}
+
+	if opts.Replay != nil {
+		mapsList, err := opts.Replay.LoadMaps(opts.MapFile)
+		if err != nil {
+			return nil, err
+		}
+		s.playback = replay.NewPlayer(opts.Replay, mapsList)
+		if s.playback == nil {
+			return nil, fmt.Errorf("no maps found in file")
+		}
+		s.game = s.playback.Game()
+		if opts.Replay.TickInterval > 0 {
+			s.tickInterval = opts.Replay.TickInterval
+		}
+	} else {
+		mapsList, err := maps.LoadMapsFromFile(opts.MapFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load maps: %v", err)
+		}
+		seed := time.Now().UnixNano()
+		s.game = gameplay.NewGameWithSeed(mapsList, opts.DisableMonsters, seed)
+		if s.game == nil {
+			return nil, fmt.Errorf("no maps found in file")
+		}
+		s.game.BustPauseTicks = s.ticksFor(bustPauseDuration)
+		s.game.Endless = opts.Endless
+		if s.recordFile != "" {
+			rec, err := replay.New(opts.MapFile, seed, s.tickInterval, s.game.BustPauseTicks, opts.DisableMonsters)
+			if err != nil {
+				return nil, err
+			}
+			rec.Endless = opts.Endless
+			s.recording = rec
+		}
+	}
+
+	s.startLevel()
+	return s, nil
+}
+
+func (s *session) ticksFor(d time.Duration) int {
+	ticks := int(d / s.tickInterval)
+	if ticks < 1 {
+		ticks = 1
+	}
+	return ticks
+}
+
+func (s *session) startLevel() {
+	s.phase = phaseLevelStart
+	s.phaseTicks = s.ticksFor(levelStartPause)
+}
+
+// handleKey steers the player. Turns are queued during the level start
+// countdown like in the GUI; playback ignores the keyboard.
+func (s *session) handleKey(k key) {
+	if s.playback != nil {
+		return
+	}
+	if s.phase != phasePlaying && s.phase != phaseLevelStart {
+		return
+	}
+
+	var d actors.Direction
+	switch k {
+	case keyUp:
+		d = actors.Up
+	case keyDown:
+		d = actors.Down
+	case keyLeft:
+		d = actors.Left
+	case keyRight:
+		d = actors.Right
+	default:
+		return
+	}
+	s.game.Player.SetDirection(d)
+	if s.recording != nil {
+		s.recording.Record(s.game.Tick, d)
+	}
+}
+
+func (s *session) step() {
+	switch s.phase {
+	case phaseLevelStart:
+		s.phaseTicks--
+		if s.phaseTicks <= 0 {
+			s.phase = phasePlaying
+		}
+		return
+	case phaseLevelComplete:
+		s.phaseTicks--
+		if s.phaseTicks <= 0 {
+			s.game.AdvanceLevel()
+			if s.game.Won {
+				s.phase = phaseOver
+				return
+			}
+			s.startLevel()
+		}
+		return
+	}
+
+	if s.playback != nil {
+		s.playback.ApplyInputs()
+	}
+	s.game.Update()
+
+	switch {
+	case s.game.GameOver || s.game.Won:
+		s.phase = phaseOver
+	case s.game.LevelCompleted:
+		s.phase = phaseLevelComplete
+		s.phaseTicks = s.ticksFor(levelCompletePause)
+	}
+}
+
+func (s *session) render() {
+	fmt.Fprint(s.out, cursorHome)
+	s.game.Render(s.out)
+
+	switch {
+	case s.phase == phaseLevelStart:
+		remaining := time.Duration(s.phaseTicks) * s.tickInterval
+		fmt.Fprintf(s.out, "\r\nGet ready... %d%s\r\n", int(remaining.Seconds())+1, clearLine)
+	case s.game.BustPaused:
+		fmt.Fprintf(s.out, "\r\n\033[31mBUSTED!\033[0m%s\r\n", clearLine)
+	case s.phase == phaseLevelComplete:
+		fmt.Fprintf(s.out, "\r\nLevel complete!%s\r\n", clearLine)
+	case s.phase == phaseOver:
+		fmt.Fprintf(s.out, "Press any key to exit%s\r\n", clearLine)
+	default:
+		fmt.Fprintf(s.out, "\r\n%s\r\n", clearLine)
+	}
+	if s.playback != nil {
+		fmt.Fprintf(s.out, "Replay (tick %d)%s\r\n", s.game.Tick, clearLine)
+	}
+	fmt.Fprint(s.out, clearToEnd)
+	s.out.Flush()
+}
+
+func (s *session) saveRecording() {
+	if s.recording == nil {
+		return
+	}
+	if err := s.recording.Save(s.recordFile); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save replay: %v\r\n", err)
+	}
+}
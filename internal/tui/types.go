@@ -0,0 +1,34 @@
+package tui
+
+import (
+	"bufio"
+	"time"
+
+	"github.com/sjiamnocna/gopucha/internal/gameplay"
+	"github.com/sjiamnocna/gopucha/internal/replay"
+)
+
+// Options configures a terminal session.
+type Options struct {
+	MapFile         string
+	DisableMonsters bool
+	Endless         bool // Loop through the levels with rising difficulty
+	TickInterval    time.Duration
+	RecordFile      string         // Save the game's inputs here on exit
+	Replay          *replay.Replay // Play this recording instead of reading the keyboard
+}
+
+type key int
+
+type phase int
+
+type session struct {
+	out          *bufio.Writer
+	game         *gameplay.Game
+	playback     *replay.Player
+	recording    *replay.Replay
+	recordFile   string
+	tickInterval time.Duration
+	phase        phase
+	phaseTicks   int // Ticks left in the level start or level complete pause
+}
@@ -3,7 +3,12 @@
 
 package ui
 
-import "time"
+import (
+	"image/color"
+	"time"
+
+	"github.com/sjiamnocna/gopucha/internal/netplay"
+)
 
 const (
 	defaultBlockSize          = 20
@@ -13,5 +18,50 @@ const (
 	statusBarHeight           = 80
 	defaultTickInterval       = 150 * time.Millisecond
 	monsterTeethBlinkInterval = 150 * time.Millisecond
+	bustPauseDuration         = 1 * time.Second
+	attractIdleDelay          = 10 * time.Second
+	frightenedBlinkInterval   = 200 * time.Millisecond
 	borderBlocks              = 1
+	autosaveFile              = "autosave.txt"
+	editorBlockSize           = 24
+	defaultEditorWidth        = 24
+	defaultEditorHeight       = 16
+	levelIntroTextSize        = 16
+)
+
+const (
+	toolWall editorTool = iota
+	toolDot
+	toolEnergizer
+	toolEmpty
+	toolPlayer
+	toolMonster
 )
+
+// editorToolNames label the editor tools, indexed by editorTool.
+var editorToolNames = []string{"Wall", "Dot", "Energizer", "Empty", "Player start", "Monster start"}
+
+// editorMaterials are offered in the editor; any other name draws classic walls.
+var editorMaterials = []string{"classic", "bricks", "graybricks", "purpledots"}
+
+// playerColors tell the Pampuch figures apart, player one first.
+var playerColors = []color.RGBA{
+	{255, 255, 0, 255},
+	{80, 220, 255, 255},
+}
+
+// hunterColor marks the human monster of a versus game while it hunts.
+var hunterColor = color.RGBA{200, 60, 255, 255}
+
+// playerColorNames name playerColors for the controls line.
+var playerColorNames = []string{"yellow", "cyan"}
+
+// netStates shows each server phase as the matching local state.
+var netStates = map[netplay.Phase]GameState{
+	netplay.PhaseWaiting:       StateWaiting,
+	netplay.PhaseCountdown:     StateLevelStart,
+	netplay.PhasePlaying:       StatePlaying,
+	netplay.PhaseLevelComplete: StateLevelComplete,
+	netplay.PhaseGameOver:      StateGameOver,
+	netplay.PhaseWon:           StateWon,
+}
@@ -0,0 +1,522 @@
+//go:build !nogui
+// +build !nogui
+
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/sjiamnocna/gopucha/internal/maps"
+)
+
+// openEditor swaps the game screen for the level editor. levels come from
+// maps.ParsePackFromFile, so levels that fail validation can be fixed here;
+// header is written back with them.
+func (g *GUIGame) openEditor(mapFile string, header maps.PackHeader, levels []maps.Map) {
+	if len(levels) == 0 {
+		levels = []maps.Map{blankLevel(defaultEditorWidth, defaultEditorHeight)}
+	}
+	g.stopGameLoop()
+	g.state = StateSettings
+	g.editor = &levelEditor{
+		file:   mapFile,
+		header: header,
+		levels: levels,
+		tool:   toolWall,
+	}
+	g.buildEditorUI()
+}
+
+// loadEditorPack reads mapFile for the editor; a missing file is a new
+// pack with no levels yet.
+func loadEditorPack(mapFile string) (maps.PackHeader, []maps.Map, error) {
+	header, levels, err := maps.ParsePackFromFile(mapFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return maps.PackHeader{}, nil, nil
+	}
+	return header, levels, err
+}
+
+// blankLevel is a walled rectangle full of dots.
+func blankLevel(width, height int) maps.Map {
+	m := maps.Map{
+		Width:         width,
+		Height:        height,
+		MonsterCount:  1,
+		SpeedModifier: 1,
+		Steering:      maps.SteeringGreedy,
+		Wrap:          maps.WrapNone,
+	}
+	for y := 0; y < height; y++ {
+		row := make([]maps.Cell, width)
+		for x := range row {
+			row[x] = maps.Dot
+			if x == 0 || y == 0 || x == width-1 || y == height-1 {
+				row[x] = maps.Wall
+			}
+		}
+		m.Cells = append(m.Cells, row)
+	}
+	return m
+}
+
+func (g *GUIGame) buildEditorUI() {
+	e := g.editor
+
+	e.levelLabel = widget.NewLabel("")
+	prevButton := widget.NewButton("◀", func() { g.showEditorLevel(e.current - 1) })
+	nextButton := widget.NewButton("▶", func() { g.showEditorLevel(e.current + 1) })
+	addButton := widget.NewButton("Add Level", func() {
+		first := e.levels[0]
+		e.levels = append(e.levels, blankLevel(first.Width, first.Height))
+		e.dirty = true
+		g.showEditorLevel(len(e.levels) - 1)
+	})
+	e.deleteButton = widget.NewButton("Delete Level", func() {
+		dialog.ShowConfirm("Delete Level", fmt.Sprintf("Delete level %d?", e.current+1), func(ok bool) {
+			if !ok || len(e.levels) < 2 {
+				return
+			}
+			e.levels = append(e.levels[:e.current], e.levels[e.current+1:]...)
+			e.dirty = true
+			g.showEditorLevel(min(e.current, len(e.levels)-1))
+		}, g.window)
+	})
+	levelBar := container.NewHBox(prevButton, e.levelLabel, nextButton, addButton, e.deleteButton)
+
+	tools := widget.NewRadioGroup(editorToolNames, func(selected string) {
+		for i, name := range editorToolNames {
+			if name == selected {
+				e.tool = editorTool(i)
+			}
+		}
+	})
+	tools.SetSelected(editorToolNames[e.tool])
+	tools.Required = true
+
+	e.nameEntry = widget.NewEntry()
+	e.nameEntry.OnChanged = func(s string) {
+		if e.loading {
+			return
+		}
+		e.level().Name = strings.TrimSpace(s)
+		g.editorChanged()
+	}
+	e.materialEntry = widget.NewSelectEntry(editorMaterials)
+	e.materialEntry.OnChanged = func(s string) {
+		if e.loading {
+			return
+		}
+		e.level().Material = strings.TrimSpace(s)
+		e.dirty = true
+		g.refreshEditorGrid()
+	}
+	e.monstersEntry = widget.NewEntry()
+	e.monstersEntry.OnChanged = func(s string) {
+		if e.loading {
+			return
+		}
+		count, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || count < 0 {
+			e.monstersProblem = fmt.Errorf("invalid monsters count: %q", s)
+		} else {
+			e.monstersProblem = nil
+			e.level().MonsterCount = count
+		}
+		g.editorChanged()
+	}
+	e.speedEntry = widget.NewEntry()
+	e.speedEntry.OnChanged = func(s string) {
+		if e.loading {
+			return
+		}
+		mod, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil || mod < 0.5 || mod > 2.0 {
+			e.speedProblem = fmt.Errorf("invalid speedModifier: %q (must be between 0.5 and 2.0)", s)
+		} else {
+			e.speedProblem = nil
+			e.level().SpeedModifier = mod
+		}
+		g.editorChanged()
+	}
+	form := widget.NewForm(
+		widget.NewFormItem("Name", e.nameEntry),
+		widget.NewFormItem("Material", e.materialEntry),
+		widget.NewFormItem("Monsters", e.monstersEntry),
+		widget.NewFormItem("Speed", e.speedEntry),
+	)
+	sidePanel := container.NewVBox(form, widget.NewSeparator(), widget.NewLabel("Paint (right click erases):"), tools)
+
+	e.grid = container.NewWithoutLayout()
+	painter := newEditorPainter(
+		func(pos fyne.Position, drag bool) { g.paintEditorAt(pos, e.tool, drag) },
+		func(pos fyne.Position) { g.paintEditorAt(pos, toolEmpty, false) },
+	)
+	first := e.levels[0]
+	gridSize := fyne.NewSize(float32(first.Width)*editorBlockSize, float32(first.Height)*editorBlockSize)
+	board := container.NewGridWrap(gridSize, container.NewStack(e.grid, painter))
+	boardArea := container.NewScroll(container.NewCenter(board))
+
+	e.status = widget.NewLabel("")
+	e.status.Wrapping = fyne.TextWrapWord
+	saveButton := widget.NewButton("Save", func() { g.saveEditor() })
+	playButton := widget.NewButton("Save & Play", func() { g.playEditor() })
+	closeButton := widget.NewButton("Close", func() { g.closeEditor() })
+	bottomBar := container.NewBorder(nil, nil, nil, container.NewHBox(saveButton, playButton, closeButton), e.status)
+
+	content := container.NewBorder(levelBar, bottomBar, sidePanel, nil, boardArea)
+	windowBg := canvas.NewRectangle(color.RGBA{0, 0, 0, 255})
+	g.window.SetContent(container.NewStack(windowBg, content))
+	g.window.Canvas().SetOnTypedKey(func(ev *fyne.KeyEvent) {
+		switch ev.Name {
+		case fyne.KeyEscape:
+			g.closeEditor()
+		case fyne.KeyPageUp:
+			g.showEditorLevel(e.current - 1)
+		case fyne.KeyPageDown:
+			g.showEditorLevel(e.current + 1)
+		}
+	})
+
+	g.showEditorLevel(0)
+}
+
+func (e *levelEditor) level() *maps.Map {
+	return &e.levels[e.current]
+}
+
+// metaProblem is the first form entry that could not be applied.
+func (e *levelEditor) metaProblem() error {
+	if e.monstersProblem != nil {
+		return e.monstersProblem
+	}
+	return e.speedProblem
+}
+
+// showEditorLevel switches to level i and loads its metadata into the form.
+func (g *GUIGame) showEditorLevel(i int) {
+	e := g.editor
+	if i < 0 || i >= len(e.levels) {
+		return
+	}
+	e.current = i
+	m := e.level()
+
+	e.loading = true
+	e.nameEntry.SetText(m.Name)
+	e.materialEntry.SetText(m.Material)
+	e.monstersEntry.SetText(strconv.Itoa(m.MonsterCount))
+	e.speedEntry.SetText(strconv.FormatFloat(m.SpeedModifier, 'g', -1, 64))
+	e.loading = false
+	e.monstersProblem, e.speedProblem = nil, nil
+
+	e.levelLabel.SetText(fmt.Sprintf("Level %d/%d", i+1, len(e.levels)))
+	if len(e.levels) > 1 {
+		e.deleteButton.Enable()
+	} else {
+		e.deleteButton.Disable()
+	}
+	g.validateEditorLevel()
+	g.refreshEditorGrid()
+}
+
+// paintEditorAt applies tool to the cell under pos, which is relative to
+// the board.
+func (g *GUIGame) paintEditorAt(pos fyne.Position, tool editorTool, drag bool) {
+	e := g.editor
+	x := int(pos.X / editorBlockSize)
+	y := int(pos.Y / editorBlockSize)
+	m := e.level()
+	if pos.X < 0 || pos.Y < 0 || x >= m.Width || y >= m.Height {
+		return
+	}
+	if drag && e.lastPainted == (maps.StartPos{X: x, Y: y}) {
+		return
+	}
+	e.lastPainted = maps.StartPos{X: x, Y: y}
+	paintCell(m, x, y, tool, drag)
+	e.loading = true
+	e.monstersEntry.SetText(strconv.Itoa(m.MonsterCount))
+	e.loading = false
+	g.editorChanged()
+}
+
+// paintCell edits one cell. Walls remove starts and teleporters under them;
+// the monster tool toggles a start on a click and only adds while dragging.
+func paintCell(m *maps.Map, x, y int, tool editorTool, drag bool) {
+	pos := maps.StartPos{X: x, Y: y}
+	if partner, ok := m.Teleports[pos]; ok {
+		delete(m.Teleports, pos)
+		delete(m.Teleports, partner)
+	}
+
+	switch tool {
+	case toolWall:
+		m.Cells[y][x] = maps.Wall
+		if m.PlayerStart != nil && *m.PlayerStart == pos {
+			m.PlayerStart = nil
+		}
+		removeMonsterStart(m, pos)
+	case toolDot:
+		m.Cells[y][x] = maps.Dot
+	case toolEnergizer:
+		m.Cells[y][x] = maps.Energizer
+	case toolEmpty:
+		m.Cells[y][x] = maps.Empty
+	case toolPlayer:
+		if m.Cells[y][x] == maps.Wall {
+			m.Cells[y][x] = maps.Empty
+		}
+		removeMonsterStart(m, pos)
+		m.PlayerStart = &pos
+	case toolMonster:
+		if m.Cells[y][x] == maps.Wall {
+			m.Cells[y][x] = maps.Empty
+		}
+		if m.PlayerStart != nil && *m.PlayerStart == pos {
+			m.PlayerStart = nil
+		}
+		if removeMonsterStart(m, pos) {
+			if drag {
+				m.MonsterStarts = append(m.MonsterStarts, pos)
+			}
+		} else {
+			m.MonsterStarts = append(m.MonsterStarts, pos)
+		}
+		// Like 'M' letters in a file, placed starts fix the monster count.
+		if len(m.MonsterStarts) > 0 {
+			m.MonsterCount = len(m.MonsterStarts)
+		}
+	}
+}
+
+func removeMonsterStart(m *maps.Map, pos maps.StartPos) bool {
+	for i, start := range m.MonsterStarts {
+		if start == pos {
+			m.MonsterStarts = append(m.MonsterStarts[:i], m.MonsterStarts[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (g *GUIGame) editorChanged() {
+	g.editor.dirty = true
+	g.validateEditorLevel()
+	g.refreshEditorGrid()
+}
+
+// validateEditorLevel runs the loader's checks on the current level and
+// shows the first problem, or failing that the first strict warning.
+func (g *GUIGame) validateEditorLevel() {
+	e := g.editor
+	m, first := e.level(), &e.levels[0]
+	e.problem, e.warning = e.metaProblem(), nil
+	if e.problem == nil && (m.Width != first.Width || m.Height != first.Height) {
+		e.problem = fmt.Errorf("level is %dx%d but level 1 is %dx%d; all levels in a file must have the same dimensions",
+			m.Width, m.Height, first.Width, first.Height)
+	}
+	if e.problem == nil {
+		e.problem = maps.Validate(m)
+	}
+	if e.problem == nil {
+		e.warning = maps.ValidateStrict(m)
+	}
+
+	text := "Level is valid"
+	if e.problem != nil {
+		text = "Problem: " + e.problem.Error()
+	} else if e.warning != nil {
+		text = "Warning: " + e.warning.Error()
+	}
+	if e.dirty {
+		text += " (unsaved)"
+	}
+	e.status.SetText(text)
+}
+
+// refreshEditorGrid redraws the board of the current level.
+func (g *GUIGame) refreshEditorGrid() {
+	e := g.editor
+	m := e.level()
+	size := float32(editorBlockSize)
+	objs := make([]fyne.CanvasObject, 0, m.Width*m.Height*2)
+
+	place := func(obj fyne.CanvasObject, x, y int, scale float32) {
+		s := size * scale
+		obj.Resize(fyne.NewSize(s, s))
+		obj.Move(fyne.NewPos(float32(x)*size+(size-s)/2, float32(y)*size+(size-s)/2))
+		objs = append(objs, obj)
+	}
+
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			place(canvas.NewRectangle(color.RGBA{0, 0, 0, 255}), x, y, 1)
+			switch m.Cells[y][x] {
+			case maps.Wall:
+				drawWallCellSized(float32(x)*size, float32(y)*size, size, x, y, m, &objs)
+			case maps.Dot:
+				place(canvas.NewCircle(color.RGBA{255, 230, 0, 255}), x, y, 0.35)
+			case maps.Energizer:
+				place(canvas.NewCircle(color.RGBA{255, 240, 200, 255}), x, y, 0.65)
+			}
+			if _, ok := m.Teleports[maps.StartPos{X: x, Y: y}]; ok {
+				ring := canvas.NewCircle(color.Transparent)
+				ring.StrokeColor = color.RGBA{200, 80, 255, 255}
+				ring.StrokeWidth = size * 0.08
+				place(ring, x, y, 0.8)
+			}
+		}
+	}
+
+	for _, pos := range m.MonsterStarts {
+		monster := canvas.NewRectangle(color.RGBA{220, 30, 30, 255})
+		monster.CornerRadius = size * 0.25
+		place(monster, pos.X, pos.Y, 0.7)
+	}
+	if m.PlayerStart != nil {
+		place(canvas.NewCircle(color.RGBA{255, 255, 0, 255}), m.PlayerStart.X, m.PlayerStart.Y, 0.8)
+	}
+
+	var cellErr *maps.CellError
+	markColor := color.RGBA{255, 40, 40, 255}
+	found := errors.As(e.problem, &cellErr)
+	if !found && errors.As(e.warning, &cellErr) {
+		found, markColor = true, color.RGBA{255, 160, 0, 255}
+	}
+	if found {
+		cells := append([]maps.StartPos{{X: cellErr.X, Y: cellErr.Y}}, cellErr.Cells...)
+		for _, c := range cells {
+			mark := canvas.NewRectangle(color.Transparent)
+			mark.StrokeColor = markColor
+			mark.StrokeWidth = 3
+			place(mark, c.X, c.Y, 1)
+		}
+	}
+
+	e.grid.Objects = objs
+	e.grid.Refresh()
+}
+
+// saveEditor writes every level back to the editor's file in order.
+func (g *GUIGame) saveEditor() bool {
+	e := g.editor
+	if err := writeMapFile(e.file, e.header, e.levels); err != nil {
+		dialog.ShowError(fmt.Errorf("failed to save %s: %v", e.file, err), g.window)
+		return false
+	}
+	e.dirty = false
+	g.validateEditorLevel()
+	return true
+}
+
+// writeMapFile replaces path through a temporary file so a failed write
+// never leaves half a map pack behind. Format 2 packs stay format 2, but
+// their comments are not kept. A symlinked pack is written through the
+// link, and an existing pack keeps its permissions.
+func writeMapFile(path string, header maps.PackHeader, levels []maps.Map) error {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".gopucha-edit-*")
+	if err != nil {
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := maps.WritePack(tmp, header, levels); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// playEditor saves and starts a game on the edited file once every level
+// passes validation.
+func (g *GUIGame) playEditor() {
+	e := g.editor
+	// Showing the levels below refills the form, so entries that could not
+	// be applied have to stop the game first.
+	if e.metaProblem() != nil {
+		g.validateEditorLevel()
+		return
+	}
+	for i := range e.levels {
+		g.showEditorLevel(i)
+		if e.problem != nil {
+			return
+		}
+	}
+	if !g.saveEditor() {
+		return
+	}
+	g.mapFile = e.file
+	g.leaveEditor()
+}
+
+func (g *GUIGame) closeEditor() {
+	if !g.editor.dirty {
+		g.leaveEditor()
+		return
+	}
+	dialog.ShowConfirm("Close Editor", "Discard unsaved changes?", func(ok bool) {
+		if ok {
+			g.leaveEditor()
+		}
+	}, g.window)
+}
+
+// leaveEditor returns to the game with a fresh start on the map file.
+func (g *GUIGame) leaveEditor() {
+	g.editor = nil
+	g.startGame()
+}
+
+func newEditorPainter(onPaint func(pos fyne.Position, drag bool), onErase func(pos fyne.Position)) *editorPainter {
+	p := &editorPainter{onPaint: onPaint, onErase: onErase}
+	p.ExtendBaseWidget(p)
+	return p
+}
+
+func (p *editorPainter) Tapped(ev *fyne.PointEvent) {
+	p.onPaint(ev.Position, false)
+}
+
+func (p *editorPainter) TappedSecondary(ev *fyne.PointEvent) {
+	p.onErase(ev.Position)
+}
+
+func (p *editorPainter) Dragged(ev *fyne.DragEvent) {
+	p.onPaint(ev.Position, true)
+}
+
+func (p *editorPainter) DragEnd() {}
+
+func (p *editorPainter) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(canvas.NewRectangle(color.Transparent))
+}
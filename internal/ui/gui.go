@@ -23,8 +23,11 @@ import (
 	"fyne.io/fyne/v2/widget"
 
 	"github.com/sjiamnocna/gopucha/internal/actors"
+	"github.com/sjiamnocna/gopucha/internal/bot"
 	"github.com/sjiamnocna/gopucha/internal/gameplay"
 	"github.com/sjiamnocna/gopucha/internal/maps"
+	"github.com/sjiamnocna/gopucha/internal/replay"
+	"github.com/sjiamnocna/gopucha/internal/scores"
 )
 
 func newKeyCatcher(onKey func(*fyne.KeyEvent)) *keyCatcher {
@@ -50,22 +53,54 @@ func (k *keyCatcher) CreateRenderer() fyne.WidgetRenderer {
 	return widget.NewSimpleRenderer(bg)
 }
 
-func RunGUIGame(mapFile string, disableMonsters bool) error {
+func RunGUIGame(opts Options) error {
 	guiGame := &GUIGame{
 		app:             app.New(),
 		blockSize:       defaultBlockSize,
 		tickInterval:    defaultTickInterval,
-		mapFile:         mapFile,
+		mapFile:         opts.MapFile,
 		state:           StateSettings,
-		disableMonsters: disableMonsters,
+		disableMonsters: opts.DisableMonsters,
+		endless:         opts.Endless,
+		timeAttack:      opts.TimeAttack,
+		coOp:            opts.CoOp,
+		versus:          opts.Versus,
+		freeTurn:        opts.FreeTurn,
+		recordFile:      opts.RecordFile,
+		replay:          opts.Replay,
+	}
+	if opts.Replay != nil {
+		if opts.MapFile == "" {
+			guiGame.mapFile = opts.Replay.MapFile
+		}
+		if opts.Replay.TickInterval > 0 {
+			guiGame.tickInterval = opts.Replay.TickInterval
+		}
+		guiGame.disableMonsters = opts.Replay.DisableMonsters
+		guiGame.endless = opts.Replay.Endless
 	}
 
 	guiGame.window = guiGame.app.NewWindow("Gopucha - Pac-Man Game")
 	guiGame.window.Resize(fyne.NewSize(800, 600))
 	guiGame.window.SetMaster()
+	guiGame.window.SetOnClosed(func() {
+		if guiGame.net != nil {
+			guiGame.net.Close()
+			return
+		}
+		guiGame.stopAttract()
+		// Let a running Update finish so the save holds a single tick.
+		guiGame.stopGameLoop()
+		guiGame.saveRecording()
+		guiGame.autosave()
+	})
 
 	// Start game immediately (settings available via ESC)
-	guiGame.startGame()
+	if opts.Join != "" {
+		guiGame.joinGame(opts.Join)
+	} else {
+		guiGame.startGame()
+	}
 
 	guiGame.window.ShowAndRun()
 	return nil
@@ -106,15 +141,102 @@ func (g *GUIGame) showSettings() {
 		mapSelect.SetSelected(mapFiles[0])
 	}
 
+	var closeSettings func(bool)
+	continueSaved := false
+	continueButton := widget.NewButton("Continue Saved Game", func() {
+		continueSaved = true
+		closeSettings(true)
+	})
+	if path, err := savePath(); err != nil || !fileExists(path) || g.replay != nil {
+		continueButton.Disable()
+	}
+
+	var editHeader maps.PackHeader
+	var editLevels []maps.Map
+	editRequested := false
+	editButton := widget.NewButton("Level Editor", func() {
+		if mapSelect.Selected == "" {
+			return
+		}
+		header, levels, err := loadEditorPack(mapSelect.Selected)
+		if err != nil {
+			dialog.ShowError(err, g.window)
+			return
+		}
+		editHeader, editLevels = header, levels
+		editRequested = true
+		closeSettings(false)
+	})
+
+	endlessCheck := widget.NewCheck("Endless mode (levels repeat, harder each cycle)", nil)
+	endlessCheck.SetChecked(g.endless)
+	timeAttackCheck := widget.NewCheck("Time attack (race par times and your bests)", nil)
+	timeAttackCheck.SetChecked(g.timeAttack)
+	// An endless game never finishes, so it cannot be timed.
+	endlessCheck.OnChanged = func(on bool) {
+		if on {
+			timeAttackCheck.SetChecked(false)
+		}
+	}
+	timeAttackCheck.OnChanged = func(on bool) {
+		if on {
+			endlessCheck.SetChecked(false)
+		}
+	}
+	coOpCheck := widget.NewCheck("Two players (arrows and WASD)", nil)
+	coOpCheck.SetChecked(g.coOp)
+	versusCheck := widget.NewCheck("Versus (WASD steers a monster)", nil)
+	versusCheck.SetChecked(g.versus)
+	freeTurnCheck := widget.NewCheck("Versus monster turns anywhere", nil)
+	freeTurnCheck.SetChecked(g.freeTurn)
+	if !g.versus {
+		freeTurnCheck.Disable()
+	}
+	// Player two either runs with Pampuch or hunts it, not both.
+	coOpCheck.OnChanged = func(on bool) {
+		if on {
+			versusCheck.SetChecked(false)
+		}
+	}
+	versusCheck.OnChanged = func(on bool) {
+		if on {
+			coOpCheck.SetChecked(false)
+			freeTurnCheck.Enable()
+		} else {
+			freeTurnCheck.Disable()
+		}
+	}
+	if g.replay != nil {
+		endlessCheck.Disable()
+		timeAttackCheck.Disable()
+		coOpCheck.Disable()
+		versusCheck.Disable()
+		freeTurnCheck.Disable()
+	}
+
+	scoresButton := widget.NewButton("High Scores", func() {
+		if mapSelect.Selected != "" {
+			g.showHighScores(mapSelect.Selected)
+		}
+	})
+
 	content := container.NewVBox(
 		widget.NewLabel("Settings"),
 		widget.NewSeparator(),
+		continueButton,
+		widget.NewSeparator(),
 		speedLabel,
 		speedSlider,
 		speedDisplay,
 		widget.NewSeparator(),
 		mapLabel,
 		mapSelect,
+		endlessCheck,
+		timeAttackCheck,
+		coOpCheck,
+		versusCheck,
+		freeTurnCheck,
+		container.NewGridWithColumns(2, scoresButton, editButton),
 	)
 
 	closed := false
@@ -123,6 +245,19 @@ func (g *GUIGame) showSettings() {
 			return
 		}
 		closed = true
+		g.stopAttract()
+		if editRequested {
+			g.openEditor(mapSelect.Selected, editHeader, editLevels)
+			return
+		}
+		if continueSaved {
+			err := g.continueGame()
+			if err == nil {
+				return
+			}
+			dialog.ShowError(fmt.Errorf("cannot continue: %v", err), g.window)
+			apply = false
+		}
 		if apply {
 			speed, _ := speedValue.Get()
 			// Invert the slider value: 550 - sliderValue = actual milliseconds
@@ -131,6 +266,13 @@ func (g *GUIGame) showSettings() {
 			if selected := mapSelect.Selected; selected != "" {
 				g.mapFile = selected
 			}
+			if g.replay == nil {
+				g.endless = endlessCheck.Checked
+				g.timeAttack = timeAttackCheck.Checked
+				g.coOp = coOpCheck.Checked
+				g.versus = versusCheck.Checked
+				g.freeTurn = freeTurnCheck.Checked
+			}
 			g.startGame()
 			g.initControls()
 			// Ensure focus after dialog closes
@@ -155,9 +297,82 @@ func (g *GUIGame) showSettings() {
 		return
 	}
 
-	g.showOverlayDialog("Settings", content, "Apply", "Cancel", func(apply bool) {
+	closeSettings = g.showOverlayDialog("Settings", content, "Apply", "Cancel", func(apply bool) {
 		handleClose(apply)
 	}, nil)
+	g.scheduleAttract()
+}
+
+// scheduleAttract starts the autopilot demo behind the settings screen once
+// it has sat idle for attractIdleDelay.
+func (g *GUIGame) scheduleAttract() {
+	if g.attractTimer != nil {
+		g.attractTimer.Stop()
+	}
+	g.attractTimer = time.AfterFunc(attractIdleDelay, func() {
+		fyne.Do(g.startAttract)
+	})
+}
+
+// startAttract puts the current game aside and lets the autopilot play a
+// fresh one from the selected map file until settings close.
+func (g *GUIGame) startAttract() {
+	if g.activeOverlay == nil || g.autopilot != nil || g.game == nil {
+		return
+	}
+	mapsList, err := maps.LoadMapsFromFile(g.mapFile)
+	if err != nil || len(mapsList) == 0 {
+		return
+	}
+
+	g.attractSaved = &attractSnapshot{
+		game:      g.game,
+		state:     g.state,
+		recording: g.recording,
+		playback:  g.playback,
+	}
+	g.recording = nil
+	g.playback = nil
+	g.demoMaps = mapsList
+	g.autopilot = bot.New()
+	g.restartDemo()
+	g.startGameLoop()
+}
+
+// restartDemo begins a new demo game; the demo loops until settings close.
+func (g *GUIGame) restartDemo() {
+	g.game = gameplay.NewGame(g.demoMaps, false)
+	g.game.BustPauseTicks = g.bustPauseTicks()
+	g.cachedMapRender = nil
+	g.calculateBlockSize()
+	g.state = StateLevelStart
+	g.countdownStart = time.Now()
+	g.pauseTicks = 0
+}
+
+// stopAttract cancels a pending demo or ends a running one and restores the
+// game it replaced. The caller decides whether to resume that game.
+func (g *GUIGame) stopAttract() {
+	if g.attractTimer != nil {
+		g.attractTimer.Stop()
+		g.attractTimer = nil
+	}
+	if g.autopilot == nil {
+		return
+	}
+	if g.ticker != nil {
+		g.ticker.Stop()
+	}
+	saved := g.attractSaved
+	g.game = saved.game
+	g.state = saved.state
+	g.recording = saved.recording
+	g.playback = saved.playback
+	g.autopilot = nil
+	g.attractSaved = nil
+	g.demoMaps = nil
+	g.cachedMapRender = nil
+	g.calculateBlockSize()
 }
 
 func (g *GUIGame) findMapFiles() []string {
@@ -198,21 +413,7 @@ func (g *GUIGame) findMapFiles() []string {
 }
 
 func (g *GUIGame) startGame() {
-	// Stop and wait for previous ticker to finish
-	if g.ticker != nil {
-		g.ticker.Stop()
-		// Wait for ticker goroutine to finish
-		if g.tickerDone != nil {
-			select {
-			case <-g.tickerDone:
-			case <-time.After(100 * time.Millisecond):
-			}
-		}
-	}
-	if g.mouthTicker != nil {
-		g.mouthTicker.Stop()
-		g.mouthTicker = nil
-	}
+	g.stopGameLoop()
 
 	if g.mapFile == "" {
 		mapFiles := g.findMapFiles()
@@ -222,7 +423,13 @@ func (g *GUIGame) startGame() {
 	}
 
 	// Load maps
-	mapsList, err := maps.LoadMapsFromFile(g.mapFile)
+	var mapsList []maps.Map
+	var err error
+	if g.replay != nil {
+		mapsList, err = g.replay.LoadMaps(g.mapFile)
+	} else {
+		mapsList, err = maps.LoadMapsFromFile(g.mapFile)
+	}
 	if err != nil {
 		g.showMapErrorAndClose(err)
 		return
@@ -233,12 +440,130 @@ func (g *GUIGame) startGame() {
 		return
 	}
 
-	g.game = gameplay.NewGame(mapsList, g.disableMonsters)
+	if g.replay != nil {
+		g.playback = replay.NewPlayer(g.replay, mapsList)
+		if g.playback != nil {
+			g.game = g.playback.Game()
+		}
+	} else {
+		seed := time.Now().UnixNano()
+		switch {
+		case g.versus:
+			g.game = gameplay.NewVersusGame(mapsList, seed, g.freeTurn)
+		case g.coOp:
+			g.game = gameplay.NewCoOpGame(mapsList, g.disableMonsters, seed)
+		default:
+			g.game = gameplay.NewGameWithSeed(mapsList, g.disableMonsters, seed)
+		}
+		if g.game != nil {
+			g.game.BustPauseTicks = g.bustPauseTicks()
+			g.game.Endless = g.endless
+			g.startRecording(seed)
+			g.openScores()
+			g.openBests()
+		}
+	}
 	if g.game == nil {
 		g.showMapErrorAndClose(fmt.Errorf("failed to create game"))
 		return
 	}
+	g.beginGame()
+}
 
+// continueGame replaces the current game with the autosaved one.
+func (g *GUIGame) continueGame() error {
+	path, err := savePath()
+	if err != nil {
+		return err
+	}
+	snap, err := gameplay.LoadSnapshot(path)
+	if err != nil {
+		return err
+	}
+	mapsList, err := snap.LoadMaps("")
+	if err != nil {
+		return err
+	}
+	game, err := gameplay.Restore(mapsList, snap)
+	if err != nil {
+		return err
+	}
+
+	g.stopGameLoop()
+	g.mapFile = snap.MapFile
+	g.game = game
+	g.playback = nil
+	// A replay has to start at tick 0, so resumed games are not recorded.
+	g.recording = nil
+	g.openScores()
+	// Splits are not saved, so a resumed run cannot set personal bests.
+	g.bests = nil
+	g.beginGame()
+	return nil
+}
+
+// autosave keeps the running game for "Continue" on the next start. A
+// finished game removes the old save instead. Two-player games are not
+// saved.
+func (g *GUIGame) autosave() {
+	if g.game == nil || g.playback != nil || g.autopilot != nil || g.net != nil || g.game.TwoPlayers() {
+		return
+	}
+	path, err := savePath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Autosave disabled: %v\n", err)
+		return
+	}
+	if g.game.GameOver || g.game.Won {
+		os.Remove(path)
+		return
+	}
+
+	snap, err := g.game.Snapshot(g.mapFile)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0o755)
+	}
+	if err == nil {
+		err = snap.Save(path)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to autosave: %v\n", err)
+	}
+}
+
+func savePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gopucha", autosaveFile), nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// stopGameLoop stops the tickers of the running game and waits briefly for
+// its loop to exit.
+func (g *GUIGame) stopGameLoop() {
+	if g.ticker != nil {
+		g.ticker.Stop()
+		if g.tickerDone != nil {
+			select {
+			case <-g.tickerDone:
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+	}
+	if g.mouthTicker != nil {
+		g.mouthTicker.Stop()
+		g.mouthTicker = nil
+	}
+}
+
+// beginGame shows g.game from its level-start countdown.
+func (g *GUIGame) beginGame() {
 	g.state = StateLevelStart
 	g.countdownStart = time.Now()
 	g.pauseTicks = 0
@@ -250,6 +575,250 @@ func (g *GUIGame) startGame() {
 	g.initControls()
 }
 
+// startRecording begins a new replay for the game just created with seed.
+// Replays hold one player's input, so two-player games are not recorded.
+func (g *GUIGame) startRecording(seed int64) {
+	g.recording = nil
+	if g.recordFile == "" || g.game.TwoPlayers() {
+		return
+	}
+	rec, err := replay.New(g.mapFile, seed, g.tickInterval, g.game.BustPauseTicks, g.disableMonsters)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Recording disabled: %v\n", err)
+		return
+	}
+	rec.Endless = g.endless
+	g.recording = rec
+}
+
+// saveRecording writes the current recording; the latest game wins.
+func (g *GUIGame) saveRecording() {
+	if g.recording == nil || g.recordFile == "" {
+		return
+	}
+	if err := g.recording.Save(g.recordFile); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save replay: %v\n", err)
+	}
+}
+
+// openScores loads the high-score table of the current map file. Replays,
+// demos, endless, time-attack and two-player games and games without
+// monsters never reach the table.
+func (g *GUIGame) openScores() {
+	g.scores = nil
+	if g.game.DisableMonsters || g.game.Endless || g.game.TwoPlayers() || g.timeAttack {
+		return
+	}
+	dir, err := scores.DefaultDir()
+	if err == nil {
+		g.scores, err = scores.Open(dir, g.mapFile)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "High scores disabled: %v\n", err)
+	}
+}
+
+// openBests loads the personal best times of the current map file for a
+// single-player time-attack game with monsters.
+func (g *GUIGame) openBests() {
+	g.bests = nil
+	if !g.timeAttack || g.game.DisableMonsters || g.game.TwoPlayers() {
+		return
+	}
+	dir, err := scores.DefaultDir()
+	if err == nil {
+		g.bests, err = scores.OpenBests(dir, g.mapFile)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Personal bests disabled: %v\n", err)
+	}
+}
+
+// timeAttackClock converts ticks to time at the default speed, so frame
+// drops never count and the speed setting cannot beat a personal best.
+func timeAttackClock(ticks int) time.Duration {
+	return time.Duration(ticks) * defaultTickInterval
+}
+
+// timeAttackRun collects the splits of the finished game.
+func (g *GUIGame) timeAttackRun() scores.Run {
+	run := scores.Run{Won: g.game.Won}
+	for _, m := range g.game.Maps {
+		run.Levels = append(run.Levels, m.Name)
+		run.Pars = append(run.Pars, m.ParTime)
+	}
+	for _, ticks := range g.game.Splits {
+		run.Splits = append(run.Splits, timeAttackClock(ticks))
+	}
+	return run
+}
+
+// showSplits compares a finished time-attack game with par and the
+// personal bests, then keeps any new bests.
+func (g *GUIGame) showSplits() {
+	if !g.timeAttack || g.game == nil || g.playback != nil {
+		return
+	}
+	run := g.timeAttackRun()
+	if len(run.Splits) == 0 {
+		return
+	}
+
+	bests := g.bests
+	if bests == nil {
+		bests = &scores.Bests{}
+	}
+	var b strings.Builder
+	bests.WriteComparison(&b, run)
+	if g.bests != nil && g.bests.Record(run) {
+		b.WriteString("\nNew personal best!")
+		if err := g.bests.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to save personal bests: %v\n", err)
+		}
+	}
+
+	text := widget.NewLabelWithStyle(b.String(), fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+	d := dialog.NewCustom("Time Attack", "Close", text, g.window)
+	d.SetOnClosed(func() {
+		if g.activeOverlay == nil && g.keyCatcher != nil {
+			g.window.Canvas().Focus(g.keyCatcher)
+		}
+	})
+	d.Show()
+}
+
+// timerText is the running time of the current level against its par.
+func (g *GUIGame) timerText() string {
+	elapsed := timeAttackClock(g.game.LevelTicks())
+	text := "Time: " + scores.FormatClock(elapsed)
+	if elapsed <= 0 {
+		text = "Time: 0:00.0"
+	}
+	if par := g.game.CurrentMap.ParTime; par > 0 {
+		text += " / Par: " + scores.FormatClock(par)
+	}
+	return text
+}
+
+// promptHighScore asks for a name when the finished game made the table.
+func (g *GUIGame) promptHighScore() {
+	table := g.scores
+	if table == nil || g.game == nil || !table.Qualifies(g.game.Score) {
+		return
+	}
+	entry := scores.Entry{
+		Score: g.game.Score,
+		Level: g.game.CurrentLevel + 1,
+		Won:   g.game.Won,
+		Date:  time.Now(),
+	}
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("Your name")
+	nameEntry.SetText(g.playerName)
+	content := container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("New high score: %d\nEnter your name:", entry.Score)),
+		nameEntry,
+	)
+	closed := false
+	choose := g.showOverlayDialog("High Score", content, "Save", "Skip", func(save bool) {
+		if closed || !save {
+			return
+		}
+		closed = true
+		entry.Name = scores.CleanName(nameEntry.Text)
+		g.playerName = entry.Name
+		table.Add(entry)
+		if err := table.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to save high scores: %v\n", err)
+		}
+		g.showHighScores(g.mapFile)
+	}, nil)
+	nameEntry.OnSubmitted = func(string) {
+		choose(true)
+	}
+	g.window.Canvas().Focus(nameEntry)
+}
+
+// showHighScores opens the table of mapFile in a dialog.
+func (g *GUIGame) showHighScores(mapFile string) {
+	dir, err := scores.DefaultDir()
+	var table *scores.Table
+	if err == nil {
+		table, err = scores.Open(dir, mapFile)
+	}
+	if err != nil {
+		dialog.ShowError(err, g.window)
+		return
+	}
+
+	var b strings.Builder
+	table.WriteReport(&b)
+	text := widget.NewLabelWithStyle(b.String(), fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+	d := dialog.NewCustom("High Scores", "Close", text, g.window)
+	d.SetOnClosed(func() {
+		if g.activeOverlay == nil && g.keyCatcher != nil {
+			g.window.Canvas().Focus(g.keyCatcher)
+		}
+	})
+	d.Show()
+}
+
+// steer queues a direction key for the player. Turns pressed during the
+// level-complete pause would be dropped together with the old player, so
+// they are not queued.
+func (g *GUIGame) steer(d actors.Direction) {
+	if g.playback != nil {
+		return
+	}
+	if g.state != StatePlaying && g.state != StateLevelStart {
+		return
+	}
+	g.queueKey(queuedKey{dir: d})
+}
+
+// steer2 queues a WASD key for the second player: the second Pampuch in
+// co-op, the human monster in versus.
+func (g *GUIGame) steer2(d actors.Direction) {
+	if g.playback != nil {
+		return
+	}
+	if g.state != StatePlaying && g.state != StateLevelStart {
+		return
+	}
+	g.queueKey(queuedKey{second: true, dir: d})
+}
+
+func (g *GUIGame) queueKey(k queuedKey) {
+	g.keysMu.Lock()
+	g.keys = append(g.keys, k)
+	g.keysMu.Unlock()
+}
+
+// applyKeys hands the queued keys to the game and the recording. The game
+// loop calls it right before Update, so every turn is recorded with the
+// tick it takes effect in, as replay.Player applies it.
+func (g *GUIGame) applyKeys() {
+	g.keysMu.Lock()
+	keys := g.keys
+	g.keys = nil
+	g.keysMu.Unlock()
+
+	for _, k := range keys {
+		switch {
+		case !k.second:
+			g.game.Player.SetDirection(k.dir)
+			if g.recording != nil {
+				g.recording.Record(g.game.Tick, k.dir)
+			}
+		case g.game.Hunter != nil:
+			g.game.Hunter.SetDirection(k.dir)
+		case g.game.Player2 != nil:
+			g.game.Player2.SetDirection(k.dir)
+		}
+	}
+}
+
 func (g *GUIGame) showMapErrorAndClose(err error) {
 	msg := widget.NewLabel(err.Error())
 	msg.Wrapping = fyne.TextWrapWord
@@ -298,22 +867,29 @@ func (g *GUIGame) setupGameUI() {
 	g.cachedMapRender = nil
 
 	// Info panel - left side stats
-	g.infoLabel = widget.NewLabel(fmt.Sprintf("%s | Score: %d | Dots: %d",
-		g.levelDisplayName(), g.game.Score, g.game.CurrentMap.CountDots()))
+	g.infoLabel = widget.NewLabel(g.statusText())
 	g.infoLabel.TextStyle = fyne.TextStyle{Bold: true}
 
-	g.controlsLabel = widget.NewLabel("Controls: Arrow Keys to move | F2 restart | +/- zoom | ESC settings")
+	g.controlsLabel = widget.NewLabel(g.controlsText())
 	g.controlsLabel.TextStyle = fyne.TextStyle{Italic: true}
 
 	// Create styled status bar background
 	statusBarBg := canvas.NewRectangle(color.RGBA{40, 40, 50, 255})
 	g.infoLabel.Importance = widget.HighImportance
 
+	// Running level time, only in time attack
+	g.timerLabel = widget.NewLabel("")
+	g.timerLabel.TextStyle = fyne.TextStyle{Bold: true, Monospace: true}
+	if !g.timeAttack {
+		g.timerLabel.Hide()
+	}
+
 	// Create status bar - only show level, score, dots and hearts (no controls line)
-	g.livesDisplay = g.createLivesDisplay(g.game.Lives)
-	g.lastLives = g.game.Lives
+	g.livesDisplay = container.NewHBox()
+	g.fillLivesDisplay()
 	topBarContent := container.NewHBox(
 		g.infoLabel,
+		g.timerLabel,
 		layout.NewSpacer(), // Flexible spacer that grows to push hearts to the far right
 		g.livesDisplay,     // Hearts display on the far right
 	)
@@ -338,7 +914,7 @@ func (g *GUIGame) setupGameUI() {
 
 	g.window.SetContent(contentWithBg)
 	g.window.Canvas().SetOnTypedKey(func(ev *fyne.KeyEvent) {
-		if ev.Name == fyne.KeyEscape {
+		if ev.Name == fyne.KeyEscape && g.net == nil {
 			g.showSettings()
 			return
 		}
@@ -406,13 +982,26 @@ func (g *GUIGame) resizeWindowForMap() {
 	g.window.Resize(fyne.NewSize(winWidth, winHeight))
 }
 
-func (g *GUIGame) createLivesDisplay(lives int) *fyne.Container {
-	heartsContainer := container.NewHBox()
-	for i := 0; i < lives; i++ {
-		heart := widget.NewLabel("❤️")
-		heartsContainer.Add(heart)
+// fillLivesDisplay shows a heart per life, per player in co-op.
+func (g *GUIGame) fillLivesDisplay() {
+	g.livesDisplay.Objects = nil
+	addHearts := func(label string, lives int) {
+		if label != "" {
+			g.livesDisplay.Add(widget.NewLabel(label))
+		}
+		for i := 0; i < lives; i++ {
+			g.livesDisplay.Add(widget.NewLabel("❤️"))
+		}
+	}
+	if g.game.CoOp {
+		addHearts("P1", g.game.Lives)
+		addHearts("P2", g.game.Lives2)
+	} else {
+		addHearts("", g.game.Lives)
 	}
-	return heartsContainer
+	g.livesDisplay.Refresh()
+	g.lastLives = g.game.Lives
+	g.lastLives2 = g.game.Lives2
 }
 
 func (g *GUIGame) initControls() {
@@ -428,7 +1017,7 @@ func (g *GUIGame) renderGame(infoLabel *widget.Label) {
 	g.renderGameAt(infoLabel, playerPos, monsterPos)
 }
 
-func (g *GUIGame) renderGameAt(infoLabel *widget.Label, playerPos renderPos, monsterPos []renderPos) {
+func (g *GUIGame) renderGameAt(infoLabel *widget.Label, playerPos, monsterPos []renderPos) {
 	if g.game == nil || g.game.CurrentMap == nil {
 		return
 	}
@@ -458,6 +1047,15 @@ func (g *GUIGame) renderGameAt(infoLabel *widget.Label, playerPos renderPos, mon
 					// Inline wall drawing to avoid extra function calls
 					g.drawWallCellIntoAt(mapOriginX+float32(x)*g.blockSize, mapOriginY+float32(y)*g.blockSize, x, y, m, &objects)
 				}
+				if _, ok := m.Teleports[maps.StartPos{X: x, Y: y}]; ok {
+					size := g.blockSize * 0.8
+					ring := canvas.NewCircle(color.Transparent)
+					ring.StrokeColor = color.RGBA{200, 80, 255, 255}
+					ring.StrokeWidth = size * 0.1
+					ring.Resize(fyne.NewSize(size, size))
+					ring.Move(fyne.NewPos(mapOriginX+float32(x)*g.blockSize+(g.blockSize-size)/2, mapOriginY+float32(y)*g.blockSize+(g.blockSize-size)/2))
+					objects = append(objects, ring)
+				}
 			}
 		}
 		if borderBlocks > 0 {
@@ -482,6 +1080,16 @@ func (g *GUIGame) renderGameAt(infoLabel *widget.Label, playerPos renderPos, mon
 	// Add dots (dynamic, eaten dots disappear)
 	for y := 0; y < m.Height; y++ {
 		for x := 0; x < m.Width; x++ {
+			if m.Cells[y][x] == maps.Energizer {
+				size := g.blockSize * 0.65
+				pellet := canvas.NewCircle(color.RGBA{255, 240, 200, 255})
+				pellet.StrokeColor = color.RGBA{255, 170, 0, 255}
+				pellet.StrokeWidth = size * 0.12
+				pellet.Resize(fyne.NewSize(size, size))
+				pellet.Move(fyne.NewPos(mapOriginX+float32(x)*g.blockSize+(g.blockSize-size)/2, mapOriginY+float32(y)*g.blockSize+(g.blockSize-size)/2))
+				g.canvas.Add(pellet)
+				continue
+			}
 			if m.Cells[y][x] == maps.Dot {
 				dotSize := g.blockSize * 0.35
 				dot := canvas.NewCircle(color.RGBA{255, 230, 0, 255})
@@ -502,25 +1110,38 @@ func (g *GUIGame) renderGameAt(infoLabel *widget.Label, playerPos renderPos, mon
 		}
 		moving := math.Abs(float64(pos.x-float32(monster.X))) > 0.001 || math.Abs(float64(pos.y-float32(monster.Y))) > 0.001
 		blinkSwap := g.monsterTeethBlinkSwap(moving)
-		g.drawMonster(mapOriginX+pos.x*g.blockSize+g.blockSize*0.1, mapOriginY+pos.y*g.blockSize+g.blockSize*0.1, g.blockSize*0.8, blinkSwap)
+		bodyColor := monsterBodyColor(&monster)
+		human := g.game.Hunter != nil && monster.Brain == g.game.Hunter
+		if human && !monster.IsFrightened() {
+			bodyColor = hunterColor
+		}
+		g.drawMonster(mapOriginX+pos.x*g.blockSize+g.blockSize*0.1, mapOriginY+pos.y*g.blockSize+g.blockSize*0.1, g.blockSize*0.8, bodyColor, blinkSwap)
+		if human {
+			g.drawHunterLabel(mapOriginX+pos.x*g.blockSize, mapOriginY+pos.y*g.blockSize)
+		}
 	}
 
-	// Render player (yellow semi-circle with mouth)
-	g.drawPacman(mapOriginX+playerPos.x*g.blockSize+g.blockSize*0.05, mapOriginY+playerPos.y*g.blockSize+g.blockSize*0.05, g.blockSize*0.9, g.game.Player.Direction)
+	// Render players (semi-circles with mouth, yellow for player one)
+	for i, p := range g.boardPlayers() {
+		if p == nil || i >= len(playerPos) {
+			continue
+		}
+		g.drawPacman(mapOriginX+playerPos[i].x*g.blockSize+g.blockSize*0.05, mapOriginY+playerPos[i].y*g.blockSize+g.blockSize*0.05, g.blockSize*0.9, p.Direction, playerColors[i])
+	}
+
+	if g.hint && g.autopilot == nil && (g.state == StatePlaying || g.state == StateLevelStart) {
+		g.drawHint(mapOriginX, mapOriginY)
+	}
 
 	// Update info
-	infoLabel.SetText(fmt.Sprintf("%s | Score: %d | Dots: %d",
-		g.levelDisplayName(), g.game.Score, g.game.CurrentMap.CountDots()))
+	infoLabel.SetText(g.statusText())
+	if g.timeAttack && g.timerLabel != nil {
+		g.timerLabel.SetText(g.timerText())
+	}
 
 	// Update lives display only when needed
-	if g.lastLives != g.game.Lives {
-		g.livesDisplay.Objects = nil
-		for i := 0; i < g.game.Lives; i++ {
-			heart := widget.NewLabel("❤️")
-			g.livesDisplay.Add(heart)
-		}
-		g.livesDisplay.Refresh()
-		g.lastLives = g.game.Lives
+	if g.lastLives != g.game.Lives || g.lastLives2 != g.game.Lives2 {
+		g.fillLivesDisplay()
 	}
 
 	// Show/hide controls based on state
@@ -536,8 +1157,21 @@ func (g *GUIGame) renderGameAt(infoLabel *widget.Label, playerPos renderPos, mon
 		pos := CenterInBand(fyne.NewSize(canvasWidth, canvasHeight), gameTop, gameHeight, size)
 		box.Move(pos)
 		g.canvas.Add(box)
+	} else if g.state == StateWaiting {
+		box := g.newWarningBox(g.waitingText(), false, canvasWidth*0.7)
+		size := box.MinSize()
+		box.Resize(size)
+		gameTop := g.currentStatusBarHeight()
+		gameHeight := canvasHeight - gameTop
+		pos := CenterInBand(fyne.NewSize(canvasWidth, canvasHeight), gameTop, gameHeight, size)
+		box.Move(pos)
+		g.canvas.Add(box)
 	} else if g.state == StateGameOver {
-		box := g.newWarningBox("Game over\nPress arrow to start again\nESC to exit", false, canvasWidth*0.7)
+		title := "Game over"
+		if g.game.Versus {
+			title = "The monster wins!"
+		}
+		box := g.newWarningBox(title+"\n"+g.againText(), false, canvasWidth*0.7)
 		size := box.MinSize()
 		box.Resize(size)
 		gameTop := g.currentStatusBarHeight()
@@ -546,7 +1180,10 @@ func (g *GUIGame) renderGameAt(infoLabel *widget.Label, playerPos renderPos, mon
 		box.Move(pos)
 		g.canvas.Add(box)
 	} else if g.state == StateWon {
-		message := fmt.Sprintf("You won!\nFinal score: %d\nPress arrow to start again\nESC to exit", g.game.Score)
+		message := fmt.Sprintf("You won!\nFinal score: %d\n%s", g.game.Score, g.againText())
+		if g.game.Versus {
+			message = fmt.Sprintf("Pampuch wins!\nScore %d to %d\n%s", g.game.Score, g.game.HunterScore, g.againText())
+		}
 		box := g.newWarningBox(message, false, canvasWidth*0.7)
 		size := box.MinSize()
 		box.Resize(size)
@@ -557,9 +1194,42 @@ func (g *GUIGame) renderGameAt(infoLabel *widget.Label, playerPos renderPos, mon
 		g.canvas.Add(box)
 	}
 
+	// Keep the settings overlay above the attract-mode demo.
+	if g.activeOverlay != nil {
+		g.canvas.Add(g.activeOverlay)
+	}
+
 	g.canvas.Refresh()
 }
 
+// againText tells how to go on after the game ended. Joined games end with
+// the server, so they can only be left.
+func (g *GUIGame) againText() string {
+	if g.net != nil {
+		return "ESC to exit"
+	}
+	return "Press arrow to start again\nESC to exit"
+}
+
+// drawHint marks the cell the autopilot would step into next.
+func (g *GUIGame) drawHint(mapOriginX, mapOriginY float32) {
+	if g.hintBot == nil {
+		g.hintBot = bot.New()
+	}
+	d, ok := g.hintBot.Suggest(g.game)
+	if !ok {
+		return
+	}
+	dx, dy := d.Delta()
+	x := g.game.Player.X + dx
+	y := g.game.Player.Y + dy
+	size := g.blockSize * 0.4
+	mark := canvas.NewCircle(color.RGBA{0, 220, 120, 200})
+	mark.Resize(fyne.NewSize(size, size))
+	mark.Move(fyne.NewPos(mapOriginX+float32(x)*g.blockSize+(g.blockSize-size)/2, mapOriginY+float32(y)*g.blockSize+(g.blockSize-size)/2))
+	g.canvas.Add(mark)
+}
+
 func (g *GUIGame) newWarningBox(text string, showControls bool, width float32) *fyne.Container {
 	if g.warningBoxCache == nil {
 		g.warningBoxCache = make(map[string]*fyne.Container)
@@ -776,7 +1446,9 @@ func (g *GUIGame) showWarningDialog(title string, content fyne.CanvasObject, okL
 }
 
 // showOverlayDialog shows a dialog overlay on top of the game without darkening the background
-func (g *GUIGame) showOverlayDialog(title string, content fyne.CanvasObject, okLabel, cancelLabel string, onChoice func(bool), keyHandler func(*fyne.KeyEvent) (bool, bool)) {
+// showOverlayDialog returns a func that closes the dialog with the given
+// choice, for content widgets that submit on their own.
+func (g *GUIGame) showOverlayDialog(title string, content fyne.CanvasObject, okLabel, cancelLabel string, onChoice func(bool), keyHandler func(*fyne.KeyEvent) (bool, bool)) func(bool) {
 	// Remove any existing overlay
 	if g.activeOverlay != nil {
 		g.canvas.Remove(g.activeOverlay)
@@ -872,6 +1544,21 @@ func (g *GUIGame) showOverlayDialog(title string, content fyne.CanvasObject, okL
 
 	g.window.Canvas().Focus(key)
 	g.canvas.Refresh()
+	return applyChoice
+}
+
+func (g *GUIGame) controlsText() string {
+	if g.net != nil {
+		return fmt.Sprintf("Online as player %d (%s): Arrow Keys to move | +/- zoom | ESC leave", g.net.Seat, playerColorNames[g.net.Seat-1])
+	}
+	move := "Arrow Keys to move"
+	switch {
+	case g.game != nil && g.game.Versus:
+		move += " (WASD steers the monster)"
+	case g.game != nil && g.game.CoOp:
+		move += " (WASD for player two)"
+	}
+	return "Controls: " + move + " | F2 restart | +/- zoom | H hint | ESC settings"
 }
 
 func (g *GUIGame) updateControlsVisibility() {
@@ -881,7 +1568,7 @@ func (g *GUIGame) updateControlsVisibility() {
 
 	// Only show controls during countdown/level start
 	if g.state == StateLevelStart && time.Since(g.countdownStart) < 3*time.Second {
-		g.controlsLabel.SetText("Controls: Arrow Keys to move | F2 restart | +/- zoom | ESC settings")
+		g.controlsLabel.SetText(g.controlsText())
 	} else {
 		g.controlsLabel.SetText("")
 	}
@@ -903,6 +1590,51 @@ func (g *GUIGame) levelDisplayName() string {
 	return name
 }
 
+// levelIntroLines describe the current level on its start screen: name,
+// author, difficulty and description, leaving out what the map does not set.
+func (g *GUIGame) levelIntroLines() []string {
+	m := g.game.CurrentMap
+	lines := []string{g.levelDisplayName()}
+	if author := strings.TrimSpace(m.Author); author != "" {
+		lines = append(lines, "by "+author)
+	}
+	if difficulty := strings.TrimSpace(m.Difficulty); difficulty != "" {
+		lines = append(lines, "Difficulty: "+difficulty)
+	}
+	if description := strings.TrimSpace(m.Description); description != "" {
+		lines = append(lines, description)
+	}
+	return lines
+}
+
+// statusText is the status bar line: level, endless cycle, scores and dots.
+func (g *GUIGame) statusText() string {
+	level := g.levelDisplayName()
+	if g.game.Endless {
+		level = fmt.Sprintf("%s | Cycle %d (x%g)", level, g.game.Cycle+1, g.game.ScoreMultiplier())
+	}
+	if g.game.CoOp {
+		return fmt.Sprintf("%s | P1: %d | P2: %d | Dots: %d", level, g.game.Score, g.game.Score2, g.game.CurrentMap.CountDots())
+	}
+	if g.game.Versus {
+		return fmt.Sprintf("%s | Pampuch: %d | Monster: %d (%d catches) | Dots: %d", level, g.game.Score, g.game.HunterScore, g.game.Catches, g.game.CurrentMap.CountDots())
+	}
+	return fmt.Sprintf("%s | Score: %d | Dots: %d", level, g.game.Score, g.game.CurrentMap.CountDots())
+}
+
+// bustPauseTicks converts the one second bust pause into game ticks at the
+// current speed setting.
+func (g *GUIGame) bustPauseTicks() int {
+	if g.tickInterval <= 0 {
+		return 1
+	}
+	ticks := int(bustPauseDuration / g.tickInterval)
+	if ticks < 1 {
+		ticks = 1
+	}
+	return ticks
+}
+
 func (g *GUIGame) currentStatusBarHeight() float32 {
 	if g.statusBarHeight > 0 {
 		return g.statusBarHeight
@@ -919,6 +1651,9 @@ func (g *GUIGame) buildBorderMap(m *maps.Map) *maps.Map {
 		for x := 0; x < width; x++ {
 			if x == 0 || y == 0 || x == width-1 || y == height-1 {
 				cells[y][x] = maps.Wall
+				if tunnelMouth(m, x-1, y-1) {
+					cells[y][x] = maps.Empty
+				}
 				continue
 			}
 			cells[y][x] = m.Cells[y-1][x-1]
@@ -933,6 +1668,18 @@ func (g *GUIGame) buildBorderMap(m *maps.Map) *maps.Map {
 	}
 }
 
+// tunnelMouth reports whether the border cell at (x, y), in map coordinates
+// just outside the grid, leads through a wrapping edge and must stay open.
+func tunnelMouth(m *maps.Map, x, y int) bool {
+	if (x < 0 || x >= m.Width) && (y < 0 || y >= m.Height) {
+		return false // Corner
+	}
+	if x < 0 || x >= m.Width {
+		return m.WrapsHorizontally() && !m.IsWall(0, y) && !m.IsWall(m.Width-1, y)
+	}
+	return m.WrapsVertically() && !m.IsWall(x, 0) && !m.IsWall(x, m.Height-1)
+}
+
 func (g *GUIGame) drawWallCell(x, y int, m *maps.Map) {
 	objs := make([]fyne.CanvasObject, 0)
 	mapOriginX, mapOriginY := g.mapOrigin()
@@ -950,7 +1697,13 @@ func (g *GUIGame) drawWallCellInto(x, y int, m *maps.Map, objs *[]fyne.CanvasObj
 }
 
 func (g *GUIGame) drawWallCellIntoAt(originX, originY float32, x, y int, m *maps.Map, objs *[]fyne.CanvasObject) {
-	line := g.blockSize * 0.08
+	drawWallCellSized(originX, originY, g.blockSize, x, y, m, objs)
+}
+
+// drawWallCellSized draws the wall at (x, y) of m in its material at any
+// block size; the level editor uses its own.
+func drawWallCellSized(originX, originY, size float32, x, y int, m *maps.Map, objs *[]fyne.CanvasObject) {
+	line := size * 0.08
 	if line < 1 {
 		line = 1
 	}
@@ -962,45 +1715,45 @@ func (g *GUIGame) drawWallCellIntoAt(originX, originY float32, x, y int, m *maps
 	hasRight := x+1 < m.Width && m.Cells[y][x+1] == maps.Wall
 	if mat == "brick" || mat == "bricks" {
 		base := canvas.NewRectangle(color.RGBA{160, 75, 25, 255})
-		base.Resize(fyne.NewSize(g.blockSize, g.blockSize))
+		base.Resize(fyne.NewSize(size, size))
 		base.Move(fyne.NewPos(originX, originY))
 		*objs = append(*objs, base)
 
 		lineColor := color.RGBA{30, 20, 10, 255}
 		if !hasTop {
 			top := canvas.NewRectangle(lineColor)
-			top.Resize(fyne.NewSize(g.blockSize, line))
+			top.Resize(fyne.NewSize(size, line))
 			top.Move(fyne.NewPos(originX, originY))
 			*objs = append(*objs, top)
 		}
 
 		if !hasBottom {
 			bottom := canvas.NewRectangle(lineColor)
-			bottom.Resize(fyne.NewSize(g.blockSize, line))
-			bottom.Move(fyne.NewPos(originX, originY+g.blockSize-line))
+			bottom.Resize(fyne.NewSize(size, line))
+			bottom.Move(fyne.NewPos(originX, originY+size-line))
 			*objs = append(*objs, bottom)
 		}
 
 		mid := canvas.NewRectangle(lineColor)
-		mid.Resize(fyne.NewSize(g.blockSize, line))
-		mid.Move(fyne.NewPos(originX, originY+g.blockSize/2-line/2))
+		mid.Resize(fyne.NewSize(size, line))
+		mid.Move(fyne.NewPos(originX, originY+size/2-line/2))
 		*objs = append(*objs, mid)
 
 		vLeft := canvas.NewRectangle(lineColor)
-		vLeft.Resize(fyne.NewSize(line, g.blockSize/2))
-		vLeft.Move(fyne.NewPos(originX+g.blockSize*0.33, originY))
+		vLeft.Resize(fyne.NewSize(line, size/2))
+		vLeft.Move(fyne.NewPos(originX+size*0.33, originY))
 		*objs = append(*objs, vLeft)
 
 		vRight := canvas.NewRectangle(lineColor)
-		vRight.Resize(fyne.NewSize(line, g.blockSize/2))
-		vRight.Move(fyne.NewPos(originX+g.blockSize*0.66, originY+g.blockSize/2))
+		vRight.Resize(fyne.NewSize(line, size/2))
+		vRight.Move(fyne.NewPos(originX+size*0.66, originY+size/2))
 		*objs = append(*objs, vRight)
 		return
 	}
 
 	if mat == "purpledots" || mat == "purple-dots" || mat == "purple dots" {
 		base := canvas.NewRectangle(color.RGBA{55, 15, 70, 255})
-		base.Resize(fyne.NewSize(g.blockSize, g.blockSize))
+		base.Resize(fyne.NewSize(size, size))
 		base.Move(fyne.NewPos(originX, originY))
 		*objs = append(*objs, base)
 
@@ -1008,9 +1761,9 @@ func (g *GUIGame) drawWallCellIntoAt(originX, originY float32, x, y int, m *maps
 		rnd := rand.New(rand.NewSource(seed))
 		dotCount := 6 + rnd.Intn(7)
 
-		centerX := originX + g.blockSize*(0.4+0.2*float32(rnd.Float64()))
-		centerY := originY + g.blockSize*(0.4+0.2*float32(rnd.Float64()))
-		clusterRadius := g.blockSize * (0.28 + 0.08*float32(rnd.Float64()))
+		centerX := originX + size*(0.4+0.2*float32(rnd.Float64()))
+		centerY := originY + size*(0.4+0.2*float32(rnd.Float64()))
+		clusterRadius := size * (0.28 + 0.08*float32(rnd.Float64()))
 		padding := float32(1)
 
 		for i := 0; i < dotCount; i++ {
@@ -1018,14 +1771,14 @@ func (g *GUIGame) drawWallCellIntoAt(originX, originY float32, x, y int, m *maps
 			radius := rnd.Float64() * float64(clusterRadius)
 			dx := float32(math.Cos(angle)) * float32(radius)
 			dy := float32(math.Sin(angle)) * float32(radius)
-			dotSize := g.blockSize * (0.06 + 0.06*float32(rnd.Float64()))
+			dotSize := size * (0.06 + 0.06*float32(rnd.Float64()))
 			xPos := centerX + dx - dotSize/2
 			yPos := centerY + dy - dotSize/2
 
 			minX := originX + padding
 			minY := originY + padding
-			maxX := originX + g.blockSize - dotSize - padding
-			maxY := originY + g.blockSize - dotSize - padding
+			maxX := originX + size - dotSize - padding
+			maxY := originY + size - dotSize - padding
 			if xPos < minX {
 				xPos = minX
 			} else if xPos > maxX {
@@ -1050,36 +1803,36 @@ func (g *GUIGame) drawWallCellIntoAt(originX, originY float32, x, y int, m *maps
 
 	if mat == "" || mat == "classic" || mat == "steel" || mat == "metal" {
 		base := canvas.NewRectangle(color.RGBA{180, 185, 195, 255})
-		base.Resize(fyne.NewSize(g.blockSize, g.blockSize))
+		base.Resize(fyne.NewSize(size, size))
 		base.Move(fyne.NewPos(originX, originY))
 		*objs = append(*objs, base)
 
 		border := color.RGBA{120, 125, 135, 255}
 		if !hasTop {
 			top := canvas.NewRectangle(border)
-			top.Resize(fyne.NewSize(g.blockSize, line))
+			top.Resize(fyne.NewSize(size, line))
 			top.Move(fyne.NewPos(originX, originY))
 			*objs = append(*objs, top)
 		}
 
 		if !hasBottom {
 			bottom := canvas.NewRectangle(border)
-			bottom.Resize(fyne.NewSize(g.blockSize, line))
-			bottom.Move(fyne.NewPos(originX, originY+g.blockSize-line))
+			bottom.Resize(fyne.NewSize(size, line))
+			bottom.Move(fyne.NewPos(originX, originY+size-line))
 			*objs = append(*objs, bottom)
 		}
 
 		if !hasLeft {
 			left := canvas.NewRectangle(border)
-			left.Resize(fyne.NewSize(line, g.blockSize))
+			left.Resize(fyne.NewSize(line, size))
 			left.Move(fyne.NewPos(originX, originY))
 			*objs = append(*objs, left)
 		}
 
 		if !hasRight {
 			right := canvas.NewRectangle(border)
-			right.Resize(fyne.NewSize(line, g.blockSize))
-			right.Move(fyne.NewPos(originX+g.blockSize-line, originY))
+			right.Resize(fyne.NewSize(line, size))
+			right.Move(fyne.NewPos(originX+size-line, originY))
 			*objs = append(*objs, right)
 		}
 		return
@@ -1087,44 +1840,44 @@ func (g *GUIGame) drawWallCellIntoAt(originX, originY float32, x, y int, m *maps
 
 	if mat == "graybricks" || mat == "gray-bricks" || mat == "gray bricks" {
 		base := canvas.NewRectangle(color.RGBA{150, 155, 160, 255})
-		base.Resize(fyne.NewSize(g.blockSize, g.blockSize))
+		base.Resize(fyne.NewSize(size, size))
 		base.Move(fyne.NewPos(originX, originY))
 		*objs = append(*objs, base)
 
 		lineColor := color.RGBA{60, 65, 70, 255}
 		if !hasTop {
 			top := canvas.NewRectangle(lineColor)
-			top.Resize(fyne.NewSize(g.blockSize, line))
+			top.Resize(fyne.NewSize(size, line))
 			top.Move(fyne.NewPos(originX, originY))
 			*objs = append(*objs, top)
 		}
 
 		if !hasBottom {
 			bottom := canvas.NewRectangle(lineColor)
-			bottom.Resize(fyne.NewSize(g.blockSize, line))
-			bottom.Move(fyne.NewPos(originX, originY+g.blockSize-line))
+			bottom.Resize(fyne.NewSize(size, line))
+			bottom.Move(fyne.NewPos(originX, originY+size-line))
 			*objs = append(*objs, bottom)
 		}
 
 		mid := canvas.NewRectangle(lineColor)
-		mid.Resize(fyne.NewSize(g.blockSize, line))
-		mid.Move(fyne.NewPos(originX, originY+g.blockSize/2-line/2))
+		mid.Resize(fyne.NewSize(size, line))
+		mid.Move(fyne.NewPos(originX, originY+size/2-line/2))
 		*objs = append(*objs, mid)
 
 		vLeft := canvas.NewRectangle(lineColor)
-		vLeft.Resize(fyne.NewSize(line, g.blockSize/2))
-		vLeft.Move(fyne.NewPos(originX+g.blockSize*0.33, originY))
+		vLeft.Resize(fyne.NewSize(line, size/2))
+		vLeft.Move(fyne.NewPos(originX+size*0.33, originY))
 		*objs = append(*objs, vLeft)
 
 		vRight := canvas.NewRectangle(lineColor)
-		vRight.Resize(fyne.NewSize(line, g.blockSize/2))
-		vRight.Move(fyne.NewPos(originX+g.blockSize*0.66, originY+g.blockSize/2))
+		vRight.Resize(fyne.NewSize(line, size/2))
+		vRight.Move(fyne.NewPos(originX+size*0.66, originY+size/2))
 		*objs = append(*objs, vRight)
 		return
 	}
 
 	defaultWall := canvas.NewRectangle(color.RGBA{0, 0, 255, 255})
-	defaultWall.Resize(fyne.NewSize(g.blockSize, g.blockSize))
+	defaultWall.Resize(fyne.NewSize(size, size))
 	defaultWall.Move(fyne.NewPos(originX, originY))
 	*objs = append(*objs, defaultWall)
 }
@@ -1146,8 +1899,31 @@ func (g *GUIGame) monsterTeethBlinkSwap(moving bool) bool {
 	return g.monsterTeethBlink
 }
 
-func (g *GUIGame) drawMonster(x, y, size float32, blinkSwap bool) {
-	bodyColor := color.RGBA{255, 0, 0, 255}
+// monsterBodyColor is red while hunting and blue while frightened,
+// flashing white shortly before the monster calms down.
+func monsterBodyColor(mo *actors.Monster) color.RGBA {
+	if !mo.IsFrightened() {
+		return color.RGBA{255, 0, 0, 255}
+	}
+	if mo.FrightenedEnding() && time.Now().UnixMilli()/frightenedBlinkInterval.Milliseconds()%2 == 0 {
+		return color.RGBA{235, 235, 255, 255}
+	}
+	return color.RGBA{40, 60, 230, 255}
+}
+
+// drawHunterLabel tags the human monster of a versus game with "P2" above
+// its cell at x, y.
+func (g *GUIGame) drawHunterLabel(x, y float32) {
+	label := canvas.NewText("P2", color.RGBA{255, 255, 255, 255})
+	label.TextSize = max(g.blockSize*0.45, 8)
+	label.TextStyle = fyne.TextStyle{Bold: true}
+	label.Alignment = fyne.TextAlignCenter
+	label.Resize(fyne.NewSize(g.blockSize, label.TextSize))
+	label.Move(fyne.NewPos(x, y-label.TextSize))
+	g.canvas.Add(label)
+}
+
+func (g *GUIGame) drawMonster(x, y, size float32, bodyColor color.RGBA, blinkSwap bool) {
 	radius := size * 0.2
 
 	// Body with rounded top corners
@@ -1187,8 +1963,31 @@ func (g *GUIGame) drawMonster(x, y, size float32, blinkSwap bool) {
 	}
 }
 
-func (g *GUIGame) capturePositions() (renderPos, []renderPos) {
-	playerPos := renderPos{x: float32(g.game.Player.X), y: float32(g.game.Player.Y)}
+// boardPlayers returns the figures to draw, indexed like playerColors. A
+// co-op player who is out is nil; a single player stays drawn after the
+// game is lost.
+func (g *GUIGame) boardPlayers() []*actors.Player {
+	if !g.game.CoOp {
+		return []*actors.Player{g.game.Player}
+	}
+	players := []*actors.Player{nil, nil}
+	if g.game.Lives > 0 {
+		players[0] = g.game.Player
+	}
+	if g.game.Lives2 > 0 {
+		players[1] = g.game.Player2
+	}
+	return players
+}
+
+func (g *GUIGame) capturePositions() ([]renderPos, []renderPos) {
+	players := g.boardPlayers()
+	playerPos := make([]renderPos, len(players))
+	for i, p := range players {
+		if p != nil {
+			playerPos[i] = renderPos{x: float32(p.X), y: float32(p.Y)}
+		}
+	}
 	monsterPos := make([]renderPos, len(g.game.Monsters))
 	for i, monster := range g.game.Monsters {
 		monsterPos[i] = renderPos{x: float32(monster.X), y: float32(monster.Y)}
@@ -1196,32 +1995,99 @@ func (g *GUIGame) capturePositions() (renderPos, []renderPos) {
 	return playerPos, monsterPos
 }
 
-func (g *GUIGame) animateMovement(infoLabel *widget.Label, startPlayer, endPlayer renderPos, startMonsters, endMonsters []renderPos) {
+// eatenDots lists what the players ate this tick.
+func (g *GUIGame) eatenDots() []eatenDot {
+	var dots []eatenDot
+	if g.game.DotEaten {
+		dots = append(dots, eatenDot{x: g.game.Player.X, y: g.game.Player.Y, energizer: g.game.EnergizerEaten})
+	}
+	if g.game.DotEaten2 {
+		dots = append(dots, eatenDot{x: g.game.Player2.X, y: g.game.Player2.Y, energizer: g.game.EnergizerEaten2})
+	}
+	return dots
+}
+
+// restoreEatenDots puts the dots eaten this tick back for the movement
+// animation; eatDots removes them again once the animation is done.
+func (g *GUIGame) restoreEatenDots(dots []eatenDot) {
+	m := g.game.CurrentMap
+	for _, d := range dots {
+		if d.y < 0 || d.y >= m.Height || d.x < 0 || d.x >= m.Width || m.Cells[d.y][d.x] != maps.Empty {
+			continue
+		}
+		if d.energizer {
+			m.Cells[d.y][d.x] = maps.Energizer
+		} else {
+			m.Cells[d.y][d.x] = maps.Dot
+		}
+	}
+}
+
+func (g *GUIGame) eatDots(dots []eatenDot) {
+	for _, d := range dots {
+		g.game.CurrentMap.EatDot(d.x, d.y)
+	}
+}
+
+// jumped reports a move of more than one cell, which is never a walk.
+func jumped(from, to renderPos) bool {
+	return math.Abs(float64(to.x-from.x))+math.Abs(float64(to.y-from.y)) > 1.001
+}
+
+// tweenStart returns where an actor moving to `to` should be drawn from.
+// Walking through a wrapping edge slides in from just outside the far side;
+// teleports and respawns snap instead of flying across the board.
+func tweenStart(m *maps.Map, from, to renderPos) renderPos {
+	if !jumped(from, to) {
+		return from
+	}
+	dx, dy := to.x-from.x, to.y-from.y
+	switch {
+	case dy == 0 && m.WrapsHorizontally() && math.Abs(float64(dx)) == float64(m.Width-1):
+		if dx > 0 {
+			return renderPos{x: to.x + 1, y: to.y}
+		}
+		return renderPos{x: to.x - 1, y: to.y}
+	case dx == 0 && m.WrapsVertically() && math.Abs(float64(dy)) == float64(m.Height-1):
+		if dy > 0 {
+			return renderPos{x: to.x, y: to.y + 1}
+		}
+		return renderPos{x: to.x, y: to.y - 1}
+	}
+	return to
+}
+
+// tweenAll places every actor progress of the way from start to end.
+func tweenAll(m *maps.Map, start, end []renderPos, progress float32) []renderPos {
+	pos := make([]renderPos, len(end))
+	for idx := range end {
+		from := renderPos{}
+		if idx < len(start) {
+			from = start[idx]
+		}
+		from = tweenStart(m, from, end[idx])
+		pos[idx] = renderPos{
+			x: from.x + (end[idx].x-from.x)*progress,
+			y: from.y + (end[idx].y-from.y)*progress,
+		}
+	}
+	return pos
+}
+
+func (g *GUIGame) animateMovement(infoLabel *widget.Label, startPlayers, endPlayers, startMonsters, endMonsters []renderPos) {
 	steps := 4 // Smoother animation without changing game speed
 	stepDuration := g.tickInterval / time.Duration(steps)
 	if stepDuration < 10*time.Millisecond {
 		stepDuration = 10 * time.Millisecond
 	}
 
+	m := g.game.CurrentMap
+
 	// Do animation synchronously but quickly
 	for i := 1; i <= steps; i++ {
 		progress := float32(i) / float32(steps)
-		playerPos := renderPos{
-			x: startPlayer.x + (endPlayer.x-startPlayer.x)*progress,
-			y: startPlayer.y + (endPlayer.y-startPlayer.y)*progress,
-		}
-
-		monsterPos := make([]renderPos, len(endMonsters))
-		for idx := range endMonsters {
-			start := renderPos{}
-			if idx < len(startMonsters) {
-				start = startMonsters[idx]
-			}
-			monsterPos[idx] = renderPos{
-				x: start.x + (endMonsters[idx].x-start.x)*progress,
-				y: start.y + (endMonsters[idx].y-start.y)*progress,
-			}
-		}
+		playerPos := tweenAll(m, startPlayers, endPlayers, progress)
+		monsterPos := tweenAll(m, startMonsters, endMonsters, progress)
 
 		fyne.DoAndWait(func() {
 			g.renderGameAt(infoLabel, playerPos, monsterPos)
@@ -1234,7 +2100,7 @@ func (g *GUIGame) animateMovement(infoLabel *widget.Label, startPlayer, endPlaye
 	}
 }
 
-func (g *GUIGame) drawPacman(x, y, size float32, dir actors.Direction) {
+func (g *GUIGame) drawPacman(x, y, size float32, dir actors.Direction, bodyColor color.RGBA) {
 	// Draw the Pac-Man circle with mouth cutout
 	// We'll draw filled arcs by creating many small filled rectangles
 
@@ -1286,7 +2152,7 @@ func (g *GUIGame) drawPacman(x, y, size float32, dir actors.Direction) {
 			px := centerX + (dist/radius)*radius*math.Cos(angle)
 			py := centerY + (dist/radius)*radius*math.Sin(angle)
 
-			pixel := canvas.NewRectangle(color.RGBA{255, 255, 0, 255}) // Yellow
+			pixel := canvas.NewRectangle(bodyColor)
 			pixel.Resize(fyne.NewSize(float32(thickness), float32(thickness)))
 			pixel.Move(fyne.NewPos(float32(px-thickness/2), float32(py-thickness/2)))
 			g.canvas.Add(pixel)
@@ -1382,6 +2248,16 @@ func (g *GUIGame) renderGameWithCountdown(infoLabel *widget.Label) {
 
 		countdownText.Move(fyne.NewPos(centerX-40, centerY-40))
 		g.canvas.Add(countdownText)
+
+		// Level credits below the countdown
+		for i, line := range g.levelIntroLines() {
+			text := canvas.NewText(line, color.RGBA{255, 255, 255, 255})
+			text.TextSize = levelIntroTextSize
+			text.Alignment = fyne.TextAlignCenter
+			text.Resize(fyne.NewSize(float32(m.Width)*g.blockSize, levelIntroTextSize*1.4))
+			text.Move(fyne.NewPos(mapOriginX, centerY+30+float32(i)*levelIntroTextSize*1.4))
+			g.canvas.Add(text)
+		}
 		g.canvas.Refresh()
 	}
 }
@@ -1441,6 +2317,11 @@ func (g *GUIGame) calculateBlockSize() {
 }
 
 func (g *GUIGame) handleKeyPress(ev *fyne.KeyEvent, infoLabel *widget.Label) {
+	if g.net != nil {
+		g.handleNetKey(ev, infoLabel)
+		return
+	}
+
 	// Handle game over / won state
 	if g.state == StateGameOver || g.state == StateWon {
 		if ev.Name == fyne.KeyEscape {
@@ -1468,26 +2349,29 @@ func (g *GUIGame) handleKeyPress(ev *fyne.KeyEvent, infoLabel *widget.Label) {
 		return
 	}
 
-	// Allow direction input during countdown/pause to queue movement
+	// Allow direction input during countdown to queue movement
 	switch ev.Name {
 	case fyne.KeyUp:
-		if g.state == StatePlaying || g.state == StateLevelStart || g.state == StateLevelComplete {
-			g.game.Player.SetDirection(actors.Up)
-		}
+		g.steer(actors.Up)
 	case fyne.KeyDown:
-		if g.state == StatePlaying || g.state == StateLevelStart || g.state == StateLevelComplete {
-			g.game.Player.SetDirection(actors.Down)
-		}
+		g.steer(actors.Down)
 	case fyne.KeyLeft:
-		if g.state == StatePlaying || g.state == StateLevelStart || g.state == StateLevelComplete {
-			g.game.Player.SetDirection(actors.Left)
-		}
+		g.steer(actors.Left)
 	case fyne.KeyRight:
-		if g.state == StatePlaying || g.state == StateLevelStart || g.state == StateLevelComplete {
-			g.game.Player.SetDirection(actors.Right)
-		}
+		g.steer(actors.Right)
+	case fyne.KeyW:
+		g.steer2(actors.Up)
+	case fyne.KeyS:
+		g.steer2(actors.Down)
+	case fyne.KeyA:
+		g.steer2(actors.Left)
+	case fyne.KeyD:
+		g.steer2(actors.Right)
 	case fyne.KeyF2:
 		g.handleF2NewGame()
+	case fyne.KeyH:
+		g.hint = !g.hint
+		g.renderGame(infoLabel)
 	case fyne.KeyEqual, fyne.KeyPlus:
 		// + to zoom in (only during playing, not during countdown/pause)
 		if g.state == StatePlaying {
@@ -1543,6 +2427,11 @@ func (g *GUIGame) zoomOut(infoLabel *widget.Label) {
 }
 
 func (g *GUIGame) startGameLoop() {
+	// Keys pressed for a previous game must not steer this one.
+	g.keysMu.Lock()
+	g.keys = nil
+	g.keysMu.Unlock()
+
 	g.tickerDone = make(chan bool)
 	g.ticker = time.NewTicker(g.tickInterval)
 
@@ -1554,27 +2443,38 @@ func (g *GUIGame) startGameLoop() {
 		tickCount := 0
 
 		for range g.ticker.C {
+			if g.autopilot != nil && (g.game.GameOver || g.game.Won) {
+				g.restartDemo()
+				continue
+			}
+
 			if g.game.GameOver {
 				g.ticker.Stop()
+				g.saveRecording()
 				g.state = StateGameOver
 				fyne.DoAndWait(func() {
 					g.renderGame(g.infoLabel)
+					g.promptHighScore()
+					g.showSplits()
 				})
 				return
 			}
 
 			if g.game.Won {
 				g.ticker.Stop()
+				g.saveRecording()
 				g.state = StateWon
 				fyne.DoAndWait(func() {
 					g.renderGame(g.infoLabel)
+					g.promptHighScore()
+					g.showSplits()
 				})
 				return
 			}
 
 			// Periodically ensure keyCatcher has focus
 			tickCount++
-			if tickCount%20 == 0 && g.keyCatcher != nil {
+			if tickCount%20 == 0 && g.keyCatcher != nil && g.activeOverlay == nil {
 				fyne.Do(func() {
 					if g.window != nil && g.window.Canvas() != nil {
 						g.window.Canvas().Focus(g.keyCatcher)
@@ -1605,7 +2505,7 @@ func (g *GUIGame) startGameLoop() {
 					continue
 				}
 				// Pause finished, move to next level
-				g.game.LoadLevel(g.game.CurrentLevel + 1)
+				g.game.AdvanceLevel()
 				g.cachedMapRender = nil // Invalidate cache for new level
 				g.state = StateLevelStart
 				g.countdownStart = time.Now()
@@ -1622,18 +2522,20 @@ func (g *GUIGame) startGameLoop() {
 			}
 
 			startPlayer, startMonsters := g.capturePositions()
+			if g.playback != nil {
+				g.playback.ApplyInputs()
+			}
+			g.applyKeys()
+			if g.autopilot != nil {
+				g.autopilot.Steer(g.game)
+			}
 			g.game.Update()
 			endPlayer, endMonsters := g.capturePositions()
 
-			pendingDot := false
-			dotX, dotY := 0, 0
-			if g.game.DotEaten {
-				dotX, dotY = g.game.Player.X, g.game.Player.Y
-				pendingDot = true
-			}
+			eaten := g.eatenDots()
 
 			// Mouth animation: smooth open/close on dot eat
-			if g.game.DotEaten {
+			if len(eaten) > 0 {
 				g.startMouthAnimation()
 			}
 
@@ -1649,8 +2551,8 @@ func (g *GUIGame) startGameLoop() {
 			if levelCompleted {
 				g.game.LevelCompleted = false
 
-				// Check if all levels are done
-				if g.game.CurrentLevel+1 >= len(g.game.Maps) {
+				// Check if all levels are done; endless games start over
+				if g.game.CurrentLevel+1 >= len(g.game.Maps) && !g.game.Endless {
 					g.game.Won = true
 				} else {
 					g.state = StateLevelComplete
@@ -1659,20 +2561,10 @@ func (g *GUIGame) startGameLoop() {
 			}
 
 			if lifeLost {
-				// Keep the dot visible during the bust animation, then remove it.
-				if pendingDot {
-					if dotY >= 0 && dotY < g.game.CurrentMap.Height && dotX >= 0 && dotX < g.game.CurrentMap.Width {
-						if g.game.CurrentMap.Cells[dotY][dotX] == maps.Empty {
-							g.game.CurrentMap.Cells[dotY][dotX] = maps.Dot
-						}
-					}
-				}
-
+				// Keep the dots visible during the bust animation, then remove them.
+				g.restoreEatenDots(eaten)
 				g.animateMovement(g.infoLabel, startPlayer, endPlayer, startMonsters, endMonsters)
-
-				if pendingDot {
-					g.game.CurrentMap.EatDot(dotX, dotY)
-				}
+				g.eatDots(eaten)
 
 				fyne.DoAndWait(func() {
 					g.renderGame(g.infoLabel)
@@ -1687,20 +2579,10 @@ func (g *GUIGame) startGameLoop() {
 				continue
 			}
 
-			// Keep the dot visible during movement animation, then remove it at the end
-			if pendingDot {
-				if dotY >= 0 && dotY < g.game.CurrentMap.Height && dotX >= 0 && dotX < g.game.CurrentMap.Width {
-					if g.game.CurrentMap.Cells[dotY][dotX] == maps.Empty {
-						g.game.CurrentMap.Cells[dotY][dotX] = maps.Dot
-					}
-				}
-			}
-
+			// Keep the dots visible during movement animation, then remove them at the end
+			g.restoreEatenDots(eaten)
 			g.animateMovement(g.infoLabel, startPlayer, endPlayer, startMonsters, endMonsters)
-
-			if pendingDot {
-				g.game.CurrentMap.EatDot(dotX, dotY)
-			}
+			g.eatDots(eaten)
 		}
 	}()
 }
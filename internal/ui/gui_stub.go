@@ -3,8 +3,6 @@
 
 package ui
 
-import "fmt"
-
-func RunGUIGame(mapFile string, disableMonsters bool) error {
-	return fmt.Errorf("GUI mode not available in this build. Rebuild with: go build -tags gui")
+func RunGUIGame(opts Options) error {
+	return ErrNoGUI
 }
@@ -0,0 +1,110 @@
+//go:build !nogui
+// +build !nogui
+
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/sjiamnocna/gopucha/internal/actors"
+	"github.com/sjiamnocna/gopucha/internal/netplay"
+)
+
+// joinGame connects to a gopucha server and shows its game. The server
+// runs the game; this window only draws it and sends the arrow keys.
+func (g *GUIGame) joinGame(addr string) {
+	client, err := netplay.Join(addr)
+	if err != nil {
+		g.showMapErrorAndClose(fmt.Errorf("cannot join %s: %v", addr, err))
+		return
+	}
+	g.net = client
+	g.game = client.Game()
+	// Animate a little faster than the server ticks so the window never
+	// falls behind the stream.
+	g.tickInterval = client.TickInterval * 3 / 4
+	g.state = StateWaiting
+	g.setupGameUI()
+	g.startNetLoop()
+}
+
+// startNetLoop draws every state the server sends until the game ends or
+// the connection drops.
+func (g *GUIGame) startNetLoop() {
+	go func() {
+		for {
+			startPlayer, startMonsters := g.capturePositions()
+			if err := g.net.Next(); err != nil {
+				fyne.Do(func() {
+					g.showMapErrorAndClose(fmt.Errorf("connection to the server lost: %v", err))
+				})
+				return
+			}
+			endPlayer, endMonsters := g.capturePositions()
+
+			state := netStates[g.net.Phase()]
+			if state == StateLevelStart && g.state != StateLevelStart {
+				g.countdownStart = time.Now()
+				g.cachedMapRender = nil // The level may have changed
+			}
+			g.state = state
+
+			switch state {
+			case StatePlaying:
+				eaten := g.eatenDots()
+				if len(eaten) > 0 {
+					g.startMouthAnimation()
+				}
+				g.restoreEatenDots(eaten)
+				g.animateMovement(g.infoLabel, startPlayer, endPlayer, startMonsters, endMonsters)
+				g.eatDots(eaten)
+			case StateLevelStart:
+				fyne.DoAndWait(func() {
+					g.renderGameWithCountdown(g.infoLabel)
+				})
+			default:
+				fyne.DoAndWait(func() {
+					g.renderGame(g.infoLabel)
+				})
+				if state == StateGameOver || state == StateWon {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// handleNetKey sends turns to the server. Restarting and settings belong
+// to the server's host, so ESC leaves the game.
+func (g *GUIGame) handleNetKey(ev *fyne.KeyEvent, infoLabel *widget.Label) {
+	switch ev.Name {
+	case fyne.KeyEscape:
+		g.window.Close()
+	case fyne.KeyUp:
+		g.net.Steer(actors.Up)
+	case fyne.KeyDown:
+		g.net.Steer(actors.Down)
+	case fyne.KeyLeft:
+		g.net.Steer(actors.Left)
+	case fyne.KeyRight:
+		g.net.Steer(actors.Right)
+	case fyne.KeyEqual, fyne.KeyPlus:
+		g.zoomIn(infoLabel)
+	case fyne.KeyMinus:
+		g.zoomOut(infoLabel)
+	}
+}
+
+// waitingText names the seats the server still waits for.
+func (g *GUIGame) waitingText() string {
+	for i, online := range g.net.Online() {
+		if !online {
+			return fmt.Sprintf("Waiting for player %d\nESC to leave", i+1)
+		}
+	}
+	return "Waiting for the server\nESC to leave"
+}
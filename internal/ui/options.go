@@ -0,0 +1,24 @@
+package ui
+
+import (
+	"errors"
+
+	"github.com/sjiamnocna/gopucha/internal/replay"
+)
+
+// ErrNoGUI is returned by RunGUIGame in builds made with the nogui tag.
+var ErrNoGUI = errors.New("GUI mode not available in this build")
+
+// Options configures a GUI session.
+type Options struct {
+	MapFile         string
+	DisableMonsters bool
+	Endless         bool           // Loop through the levels with rising difficulty
+	TimeAttack      bool           // Time each level against par and personal bests
+	CoOp            bool           // Two players on one keyboard, arrows and WASD
+	Versus          bool           // Player two steers a monster with WASD
+	FreeTurn        bool           // The versus monster may turn anywhere
+	RecordFile      string         // Save each finished game's inputs here
+	Replay          *replay.Replay // Play this recording instead of reading the keyboard
+	Join            string         // Play on the gopucha server at this host:port
+}
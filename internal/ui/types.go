@@ -4,11 +4,18 @@
 package ui
 
 import (
+	"sync"
 	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/widget"
+	"github.com/sjiamnocna/gopucha/internal/actors"
+	"github.com/sjiamnocna/gopucha/internal/bot"
 	"github.com/sjiamnocna/gopucha/internal/gameplay"
+	"github.com/sjiamnocna/gopucha/internal/maps"
+	"github.com/sjiamnocna/gopucha/internal/netplay"
+	"github.com/sjiamnocna/gopucha/internal/replay"
+	"github.com/sjiamnocna/gopucha/internal/scores"
 )
 
 type GameState int
@@ -20,6 +27,7 @@ const (
 	StateLevelComplete
 	StateGameOver
 	StateWon
+	StateWaiting // Networked game waiting for players to join
 )
 
 type GUIGame struct {
@@ -40,6 +48,7 @@ type GUIGame struct {
 	controlsLabel         *widget.Label
 	livesDisplay          *fyne.Container // Hearts display for lives
 	lastLives             int
+	lastLives2            int
 	state                 GameState
 	countdownStart        time.Time
 	pauseTicks            int
@@ -49,12 +58,91 @@ type GUIGame struct {
 	mouthAnimDir          int
 	mouthTicker           *time.Ticker
 	disableMonsters       bool
+	endless               bool // Start games in endless mode
+	timeAttack            bool // Start games in time-attack mode
+	coOp                  bool // Start two-player games
+	versus                bool // Start games with a human monster
+	freeTurn              bool // The human monster may turn anywhere
+	timerLabel            *widget.Label
 	monsterTeethBlink     bool
 	monsterTeethBlinkLast time.Time
 	cachedMapRender       []fyne.CanvasObject // Cached static map layer
 	warningBoxCache       map[string]*fyne.Container
 	activeWarningPopup    *widget.PopUp
 	activeOverlay         *fyne.Container // For transparent overlays (like settings)
+	recordFile            string
+	recording             *replay.Replay // Inputs of the running game, nil unless recording
+	replay                *replay.Replay // Recording being played back instead of keyboard input
+	playback              *replay.Player
+	autopilot             *bot.Autopilot   // Drives the attract-mode demo, nil otherwise
+	attractTimer          *time.Timer      // Fires when the settings screen has been idle long enough
+	attractSaved          *attractSnapshot // Game put aside while the demo runs
+	demoMaps              []maps.Map
+	hint                  bool
+	hintBot               *bot.Autopilot
+	scores                *scores.Table   // High scores of the map file, nil when the game cannot enter them
+	playerName            string          // Last name entered for a high score
+	bests                 *scores.Bests   // Time-attack bests of the map file, nil when the game cannot set them
+	editor                *levelEditor    // Open level editor, nil while playing
+	net                   *netplay.Client // Server connection of a joined game, nil otherwise
+	keysMu                sync.Mutex
+	keys                  []queuedKey // Turns pressed since the last tick, applied by the game loop
+}
+
+// queuedKey is a direction key waiting for the next tick.
+type queuedKey struct {
+	second bool // WASD: player two in co-op, the human monster in versus
+	dir    actors.Direction
+}
+
+// attractSnapshot holds what the demo replaces so closing settings can
+// bring it back.
+type attractSnapshot struct {
+	game      *gameplay.Game
+	state     GameState
+	recording *replay.Replay
+	playback  *replay.Player
+}
+
+// levelEditor holds the map pack being edited and the editor's widgets.
+type levelEditor struct {
+	file            string
+	header          maps.PackHeader // Written back on save
+	levels          []maps.Map
+	current         int
+	tool            editorTool
+	dirty           bool
+	loading         bool  // Form is being filled from a level, not edited
+	problem         error // First validation problem of the current level
+	warning         error // First strict check the level fails, such as a dot trap
+	monstersProblem error // Monsters entry that could not be applied
+	speedProblem    error // Speed entry that could not be applied
+	lastPainted     maps.StartPos
+	grid            *fyne.Container
+	status          *widget.Label
+	levelLabel      *widget.Label
+	deleteButton    *widget.Button
+	nameEntry       *widget.Entry
+	materialEntry   *widget.SelectEntry
+	monstersEntry   *widget.Entry
+	speedEntry      *widget.Entry
+}
+
+// editorTool is what painting a cell in the level editor puts there.
+type editorTool int
+
+// editorPainter catches clicks and drags over the editor board.
+type editorPainter struct {
+	widget.BaseWidget
+	onPaint func(pos fyne.Position, drag bool)
+	onErase func(pos fyne.Position)
+}
+
+// eatenDot is a dot eaten this tick, kept on screen until the move is drawn.
+type eatenDot struct {
+	x         int
+	y         int
+	energizer bool
 }
 
 type renderPos struct {